@@ -18,17 +18,22 @@
 //
 // Binary Grammar
 //
-// binary ::= b b1 b0 <binary-data> binary
+// binary ::= x41 b1 b0 <binary-data> binary
 //        ::= B b1 b0 <binary-data>
 //        ::= [x20-x2f] <binary-data>
+//        ::= [x34-x37] b0 <binary-data>
 //
 // Binary data is encoded in chunks. The octet x42 ('B') encodes the final chunk
-// and x62 ('b') represents any non-final chunk. Each chunk has a 16-bit // length value.
+// and x41 ('A') represents any non-final chunk. Each chunk has a 16-bit // length value.
 // 	len = 256 * b1 + b0
 //
 // short binary
 // Binary data with length less than 15 may be encoded by a single octet length [x20-x2f].
 // 	len = code - 0x20
+//
+// medium binary
+// Binary data with length less than 1024 may be encoded by [x34-x37] and a length octet.
+// 	len = 256 * (code - 0x34) + b0
 
 package hessian
 
@@ -42,10 +47,13 @@ import (
 const (
 	_binaryChunkSize      = 4096
 	_binaryFinalChunk     = byte('B')  // final chunk
-	_binaryChunk          = byte('b')  // non-final chunk
+	_binaryChunk          = byte('A')  // non-final chunk
 	_binaryShortLenTagMin = byte(0x20) // 1-byte length binary min
 	_binaryShortLenTagMax = byte(0x2f) // 1-byte length binary max
 	_binaryShortTagMaxLen = int(_binaryShortLenTagMax - _binaryShortLenTagMin)
+
+	_binaryMediumLenTagMin = byte(0x34) // 2-byte length binary min
+	_binaryMediumLenTagMax = byte(0x37) // 2-byte length binary max
 )
 
 var (
@@ -98,9 +106,9 @@ func decodeBinaryValue(reader ByteRuneReader, flag int32) ([]byte, error) {
 		return nil, err
 	}
 
-	// ----> nil binary
+	// ----> empty binary
 	if tag == _binaryShortLenTagMin {
-		return nil, nil
+		return []byte{}, nil
 	}
 
 	length, err := getBinaryLen(reader, tag)
@@ -108,7 +116,7 @@ func decodeBinaryValue(reader ByteRuneReader, flag int32) ([]byte, error) {
 		return nil, err
 	}
 
-	byteBuf := bytes.NewBuffer(nil)
+	byteBuf := bytes.NewBuffer(make([]byte, 0, length))
 	buf := make([]byte, length)
 
 	for {
@@ -130,7 +138,8 @@ func decodeBinaryValue(reader ByteRuneReader, flag int32) ([]byte, error) {
 			}
 			return nil, err
 		}
-		if !binaryTag(tag) {
+		// the non-final chunk of hessian 1.0 is accepted too
+		if !binaryTag(tag) && tag != _binary1Chunk {
 			return nil, fmt.Errorf("error binary tag: 0x%x", tag)
 		}
 
@@ -138,10 +147,10 @@ func decodeBinaryValue(reader ByteRuneReader, flag int32) ([]byte, error) {
 		if err != nil {
 			return nil, err
 		}
-		if newLength < length {
-			buf = buf[:newLength]
-			length = newLength
+		if newLength > cap(buf) {
+			buf = make([]byte, newLength)
 		}
+		buf = buf[:newLength]
 	}
 
 	return byteBuf.Bytes(), nil
@@ -151,16 +160,20 @@ func binaryShortTag(tag byte) bool {
 	return tag >= _binaryShortLenTagMin && tag <= _binaryShortLenTagMax
 }
 
+func binaryMediumTag(tag byte) bool {
+	return tag >= _binaryMediumLenTagMin && tag <= _binaryMediumLenTagMax
+}
+
 func binaryChunkTag(tag byte) bool {
 	return tag == _binaryFinalChunk || tag == _binaryChunk
 }
 
 func binaryEndTag(tag byte) bool {
-	return tag == _binaryFinalChunk || binaryShortTag(tag)
+	return tag == _binaryFinalChunk || binaryShortTag(tag) || binaryMediumTag(tag)
 }
 
 func binaryTag(tag byte) bool {
-	return binaryShortTag(tag) || binaryChunkTag(tag)
+	return binaryShortTag(tag) || binaryMediumTag(tag) || binaryChunkTag(tag)
 }
 
 func getBinaryLen(reader ByteRuneReader, tag byte) (int, error) {
@@ -168,6 +181,14 @@ func getBinaryLen(reader ByteRuneReader, tag byte) (int, error) {
 		return int(tag - _binaryShortLenTagMin), nil
 	}
 
+	if binaryMediumTag(tag) {
+		bs, err := readBytes(reader, 1)
+		if err != nil {
+			return 0, err
+		}
+		return int(tag-_binaryMediumLenTagMin)<<8 + int(bs[0]), nil
+	}
+
 	bs := make([]byte, 2)
 	_, err := io.ReadFull(reader, bs)
 	if err != nil {
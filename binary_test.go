@@ -70,3 +70,49 @@ func binaryTest(t *testing.T, buf []byte) {
 		assert.True(t, reflect.DeepEqual(buf, decodeBt))
 	}
 }
+
+func TestBinaryChunkTags(t *testing.T) {
+	buf := make([]byte, _binaryChunkSize+1)
+	encodeBt := encodeBinary(buf)
+	assert.Equal(t, byte('A'), encodeBt[0])
+
+	// medium binary and hessian 1.0 non-final chunk
+	for _, encoded := range [][]byte{
+		{0x34, 0x03, 0x01, 0x02, 0x03},
+		{'b', 0x00, 0x01, 0x01, 'B', 0x00, 0x02, 0x02, 0x03},
+	} {
+		decodeBt, err := decodeBinary(bufio.NewReader(bytes.NewReader(encoded)))
+		assert.Nil(t, err)
+		assert.Equal(t, []byte{1, 2, 3}, decodeBt)
+	}
+}
+
+// the bytes written by java Hessian2Output.writeBytes, whose buffer size is 8192,
+// the non-final chunks fill the rest of the buffer, so that the chunks are of different lengths.
+func TestBinaryJavaChunks(t *testing.T) {
+	data := make([]byte, 10000)
+	_, err := rand.Read(data)
+	assert.Nil(t, err)
+
+	join := func(parts ...[]byte) []byte {
+		return bytes.Join(parts, nil)
+	}
+
+	for _, c := range []struct {
+		encoded []byte
+		data    []byte
+	}{
+		// 15 bytes
+		{join([]byte{0x2f}, data[:15]), data[:15]},
+		// 1000 bytes
+		{join([]byte{0x37, 0xe8}, data[:1000]), data[:1000]},
+		// 10000 bytes at the buffer offset 0
+		{join([]byte{'A', 0x1f, 0xfd}, data[:8189], []byte{'B', 0x07, 0x13}, data[8189:]), data},
+		// 10000 bytes at the buffer offset 8000, the second chunk is larger than the first one
+		{join([]byte{'A', 0x00, 0xbd}, data[:189], []byte{'A', 0x1f, 0xfd}, data[189:8378], []byte{'B', 0x06, 0x56}, data[8378:]), data},
+	} {
+		decodeBt, err := decodeBinary(bufio.NewReader(bytes.NewReader(c.encoded)))
+		assert.Nil(t, err)
+		assert.Equal(t, c.data, decodeBt)
+	}
+}
@@ -0,0 +1,446 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+// see: http://hessian.caucho.com/doc/hessian-ws.html
+//
+// Call Grammar
+//
+// top     ::= version content
+// version ::= 'H' x02 x00
+// content ::= call
+//         ::= fault
+//         ::= reply
+//
+// call    ::= 'C' string int value*
+// fault   ::= 'F' map
+// reply   ::= 'R' value
+//
+// A call contains the method name, the number of arguments and the arguments.
+// A reply contains the return value, or a fault map with the keys "code", "message" and "detail".
+//
+// The envelope tags conflict with the serialization grammar:
+// 'C' is the class-def tag, 'R' is a non-final string chunk, 'F' is boolean false and 'H' is an untyped map.
+// So a call or a reply MUST be read by ReadCall/ReadReply at the start of a message, never by ReadData.
+// The deflation envelopes before the content are unwrapped by ReadCall/ReadReply.
+//
+// -------------- Call examples
+//
+// ----------> obj.add2(2,3) call
+//
+// H x02 x00         # hessian 2.0
+// C                 # RPC call
+//   x04 add2        # method "add2"
+//   x92             # two arguments
+//   x92             # 2 - argument 1
+//   x93             # 3 - argument 2
+//
+// ----------> obj.add2(2,3) reply
+//
+// H x02 x00         # hessian 2.0
+// R                 # reply
+//   x95             # int 5
+//
+// ----------> obj.add2(2,3) fault
+//
+// H x02 x00         # hessian 2.0
+// F                 # fault
+//   H               # untyped map
+//   x04 code
+//   x10 ServiceException
+//   x07 message
+//   x0e File Not Found
+//   Z
+//
+// -------------- Hessian 1.0
+//
+// call    ::= 'c' x01 x00 header* 'm' b16 b8 method-string value* 'z'
+// reply   ::= 'r' x01 x00 header* value 'z'
+//         ::= 'r' x01 x00 header* 'f' (string value)* 'z'
+// header  ::= 'H' b16 b8 header-string value
+//
+// The envelopes of hessian 1.0 are written if the encoder is created with WithProtocolVersion(ProtocolVersion1),
+// and they are always accepted by ReadCall/ReadReply, the headers are skipped.
+
+package hessian
+
+import (
+	"io"
+	"reflect"
+)
+
+const (
+	_versionTag   = byte('H')
+	_versionMajor = byte(0x02)
+	_versionMinor = byte(0x00)
+
+	_callTag  = byte('C')
+	_replyTag = byte('R')
+	_faultTag = byte('F')
+
+	_faultCodeKey    = "code"
+	_faultMessageKey = "message"
+	_faultDetailKey  = "detail"
+
+	// _maxCallArgs is the max argument count of a call, a java method has at most 255 parameters.
+	_maxCallArgs = 255
+)
+
+// fault codes defined by hessian
+const (
+	FaultProtocolException      = "ProtocolException"
+	FaultNoSuchObjectException  = "NoSuchObjectException"
+	FaultNoSuchMethodException  = "NoSuchMethodException"
+	FaultRequireHeaderException = "RequireHeaderException"
+	FaultServiceException       = "ServiceException"
+)
+
+// Call a hessian method call
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// Fault a hessian fault reply, it's returned as the error of ReadReply
+type Fault struct {
+	Code    string
+	Message string
+	Detail  interface{}
+}
+
+func (f *Fault) Error() string {
+	return "hessian fault " + f.Code + ": " + f.Message
+}
+
+// WriteCall write a method call with the arguments
+func (e *Encoder) WriteCall(method string, args ...interface{}) error {
+	if e.isHessian1() {
+		return e.writeCall1(method, args)
+	}
+
+	e.writeVersion()
+	e.writeBT(_callTag)
+	e.writeString(method)
+	e.writeInt(int32(len(args)))
+	for _, arg := range args {
+		if _, err := e.WriteData(arg); err != nil {
+			return newCodecError("WriteCall", err)
+		}
+	}
+	return nil
+}
+
+// WriteReply write a reply with the return value
+func (e *Encoder) WriteReply(value interface{}) error {
+	if e.isHessian1() {
+		e.writeBT(_reply1Tag, _version1Major, _version1Minor)
+		if _, err := e.WriteData(value); err != nil {
+			return newCodecError("WriteReply", err)
+		}
+		e.writeBT(_end1Flag)
+		return nil
+	}
+
+	e.writeVersion()
+	e.writeBT(_replyTag)
+	if _, err := e.WriteData(value); err != nil {
+		return newCodecError("WriteReply", err)
+	}
+	return nil
+}
+
+// WriteFault write a fault reply, the detail is ignored if nil
+func (e *Encoder) WriteFault(code, message string, detail interface{}) error {
+	if e.isHessian1() {
+		e.writeBT(_reply1Tag, _version1Major, _version1Minor, _fault1Tag)
+	} else {
+		e.writeVersion()
+		e.writeBT(_faultTag, _mapUntypedTag)
+
+		// the fault map takes a ref as any other map
+		e.addRefPlaceholder()
+	}
+
+	e.writeString(_faultCodeKey)
+	e.writeString(code)
+	e.writeString(_faultMessageKey)
+	e.writeString(message)
+	if detail != nil {
+		e.writeString(_faultDetailKey)
+		if _, err := e.WriteData(detail); err != nil {
+			return newCodecError("WriteFault", err)
+		}
+	}
+	e.writeBT(e.endFlag())
+	return nil
+}
+
+func (e *Encoder) writeCall1(method string, args []interface{}) error {
+	e.writeBT(_call1Tag, _version1Major, _version1Minor)
+	if _, err := e.writeLenString1(_method1Tag, method); err != nil {
+		return newCodecError("WriteCall", err)
+	}
+	for _, arg := range args {
+		if _, err := e.WriteData(arg); err != nil {
+			return newCodecError("WriteCall", err)
+		}
+	}
+	e.writeBT(_end1Flag)
+	return nil
+}
+
+func (e *Encoder) writeVersion() (int, error) {
+	return e.writeBT(_versionTag, _versionMajor, _versionMinor)
+}
+
+// ReadCall read a method call
+func (d *Decoder) ReadCall() (*Call, error) {
+	tag, err := d.readContentTag()
+	if err != nil {
+		return nil, newCodecError("ReadCall", err)
+	}
+	if tag == _call1Tag {
+		return d.readCall1()
+	}
+	if tag != _callTag {
+		return nil, newCodecError("ReadCall", "error call tag: 0x%x", tag)
+	}
+
+	method, err := d.readString(_tagRead)
+	if err != nil {
+		return nil, newCodecError("ReadCall", "read method", err)
+	}
+	count, err := d.readInt(_tagRead)
+	if err != nil {
+		return nil, newCodecError("ReadCall", "read argument count", err)
+	}
+	if count < 0 || count > _maxCallArgs {
+		return nil, newCodecError("ReadCall", "error argument count: %d", count)
+	}
+
+	args := make([]interface{}, 0, count)
+	for i := int32(0); i < count; i++ {
+		// ReadData ignores the read error of the tag, so check the end of the stream first
+		if _, err = d.readTag(); err != nil {
+			return nil, newCodecError("ReadCall", "read argument %d of %d", i, count, io.ErrUnexpectedEOF)
+		}
+		if err = d.unreadTag(); err != nil {
+			return nil, newCodecError("ReadCall", "read argument %d", i, err)
+		}
+		arg, err := EnsureInterface(d.ReadData())
+		if err != nil {
+			return nil, newCodecError("ReadCall", "read argument %d", i, err)
+		}
+		args = append(args, arg)
+	}
+	return &Call{Method: method, Args: args}, nil
+}
+
+// ReadReply read a reply and return the value,
+// a *Fault error will be returned if it's a fault reply.
+func (d *Decoder) ReadReply() (interface{}, error) {
+	tag, err := d.readContentTag()
+	if err != nil {
+		return nil, newCodecError("ReadReply", err)
+	}
+
+	switch tag {
+	case _reply1Tag:
+		return d.readReply1()
+	case _replyTag:
+		return d.ReadObject()
+	case _faultTag:
+		fault, err := d.readFault()
+		if err != nil {
+			return nil, err
+		}
+		return nil, fault
+	default:
+		return nil, newCodecError("ReadReply", "error reply tag: 0x%x", tag)
+	}
+}
+
+func (d *Decoder) readFault() (*Fault, error) {
+	m, err := d.ReadObject()
+	if err != nil {
+		return nil, newCodecError("readFault", err)
+	}
+	return newFault(m)
+}
+
+// create fault from the decoded fault map
+func newFault(m interface{}) (*Fault, error) {
+	fault := &Fault{}
+	mv := reflect.ValueOf(m)
+	if mv.Kind() != reflect.Map {
+		return nil, newCodecError("newFault", "expect fault map, but get %v", m)
+	}
+	for _, k := range mv.MapKeys() {
+		key, ok := k.Interface().(string)
+		if !ok {
+			continue
+		}
+		value := mv.MapIndex(k).Interface()
+		switch key {
+		case _faultCodeKey:
+			fault.Code, _ = value.(string)
+		case _faultMessageKey:
+			fault.Message, _ = value.(string)
+		case _faultDetailKey:
+			fault.Detail = value
+		}
+	}
+	return fault, nil
+}
+
+// readContentTag skip the optional version header and unwrap the envelopes, and return the tag of the content
+func (d *Decoder) readContentTag() (byte, error) {
+	tag, err := d.readVersion()
+	for err == nil && tag == _envelopeTag {
+		if err = d.unwrapEnvelope(); err == nil {
+			tag, err = d.readVersion()
+		}
+	}
+	return tag, err
+}
+
+// readVersion skip the optional version header, and return the tag of the content
+func (d *Decoder) readVersion() (byte, error) {
+	tag, err := d.readTag()
+	if err != nil {
+		return 0, err
+	}
+	if tag != _versionTag {
+		return tag, nil
+	}
+
+	version, err := d.readBytes(2)
+	if err != nil {
+		return 0, err
+	}
+	if version[0] != _versionMajor {
+		return 0, newCodecError("readVersion", "unsupported version: %d.%d", version[0], version[1])
+	}
+	return d.readTag()
+}
+
+// readCall1 read hessian 1.0 call after the tag 'c'
+func (d *Decoder) readCall1() (*Call, error) {
+	tag, err := d.readHeaders1()
+	if err != nil {
+		return nil, newCodecError("ReadCall", err)
+	}
+	if tag != _method1Tag {
+		return nil, newCodecError("ReadCall", "error method tag: 0x%x", tag)
+	}
+	method, err := d.readLenString1()
+	if err != nil {
+		return nil, newCodecError("ReadCall", "read method", err)
+	}
+
+	args := make([]interface{}, 0)
+	for {
+		// readData1 ignores the read error of the tag, so read the tag first
+		tag, err := d.readTag()
+		if err != nil {
+			return nil, newCodecError("ReadCall", "read argument %d", len(args), err)
+		}
+		if tag == _end1Flag {
+			break
+		}
+		if len(args) >= _maxCallArgs {
+			return nil, newCodecError("ReadCall", "too many arguments")
+		}
+		arg, err := EnsureInterface(d.readData1(int32(tag)))
+		if err != nil {
+			return nil, newCodecError("ReadCall", "read argument %d", len(args), err)
+		}
+		args = append(args, arg)
+	}
+	return &Call{Method: method, Args: args}, nil
+}
+
+// readReply1 read hessian 1.0 reply after the tag 'r'
+func (d *Decoder) readReply1() (interface{}, error) {
+	tag, err := d.readHeaders1()
+	if err != nil {
+		return nil, newCodecError("ReadReply", err)
+	}
+
+	if tag == _fault1Tag {
+		m := make(map[interface{}]interface{})
+		for {
+			tag, err := d.readTag()
+			if err != nil {
+				return nil, newCodecError("ReadReply", "read fault", err)
+			}
+			if tag == _end1Flag {
+				break
+			}
+			key, err := EnsureInterface(d.readData1(int32(tag)))
+			if err != nil {
+				return nil, newCodecError("ReadReply", "read fault", err)
+			}
+			if tag, err = d.readTag(); err != nil {
+				return nil, newCodecError("ReadReply", "read fault", err)
+			}
+			if m[key], err = EnsureInterface(d.readData1(int32(tag))); err != nil {
+				return nil, newCodecError("ReadReply", "read fault", err)
+			}
+		}
+		fault, err := newFault(m)
+		if err != nil {
+			return nil, err
+		}
+		return nil, fault
+	}
+
+	value, err := EnsureInterface(d.readData1(int32(tag)))
+	if err != nil {
+		return nil, newCodecError("ReadReply", err)
+	}
+	if tag, err = d.readTag(); err != nil {
+		return nil, newCodecError("ReadReply", err)
+	}
+	if tag != _end1Flag {
+		return nil, newCodecError("ReadReply", "error reply end tag: 0x%x", tag)
+	}
+	return value, nil
+}
+
+// readHeaders1 read the version and skip the headers of hessian 1.0 call or reply,
+// and return the tag after the headers.
+func (d *Decoder) readHeaders1() (byte, error) {
+	version, err := d.readBytes(2)
+	if err != nil {
+		return 0, err
+	}
+	if version[0] != _version1Major {
+		return 0, newCodecError("readHeaders1", "unsupported version: %d.%d", version[0], version[1])
+	}
+	d.protocolVersion = ProtocolVersion1
+
+	for {
+		tag, err := d.readTag()
+		if err != nil || tag != _header1Tag {
+			return tag, err
+		}
+		if _, err = d.readLenString1(); err != nil {
+			return 0, err
+		}
+		if _, err = d.readData1(_tagRead); err != nil {
+			return 0, err
+		}
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package hessian
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCall(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	e := NewEncoder(buf, nil)
+	assert.Nil(t, e.WriteCall("add2", int32(2), int32(3)))
+	assert.Equal(t, []byte{'H', 0x02, 0x00, 'C', 0x04, 'a', 'd', 'd', '2', 0x92, 0x92, 0x93}, buf.Bytes())
+
+	d := NewDecoder(bufio.NewReader(buf), nil)
+	call, err := d.ReadCall()
+	assert.Nil(t, err)
+	assert.Equal(t, "add2", call.Method)
+	assert.Equal(t, []interface{}{int32(2), int32(3)}, call.Args)
+}
+
+func TestCallObjectArgs(t *testing.T) {
+	p := &P{X: 1, Y: 2, Z: 3, Name: "point"}
+	typMap, nameMap := ExtractTypeNameMap(p)
+
+	buf := bytes.NewBuffer(nil)
+	e := NewEncoder(buf, nameMap)
+	assert.Nil(t, e.WriteCall("move", p, p, "up"))
+
+	d := NewDecoder(bufio.NewReader(buf), typMap)
+	call, err := d.ReadCall()
+	assert.Nil(t, err)
+	assert.Equal(t, "move", call.Method)
+	assert.Equal(t, 3, len(call.Args))
+	assert.Equal(t, p, call.Args[0])
+	assert.True(t, AddrEqual(call.Args[0], call.Args[1]))
+	assert.Equal(t, "up", call.Args[2])
+}
+
+func TestCallWithoutVersion(t *testing.T) {
+	data := []byte{'C', 0x04, 'a', 'd', 'd', '2', 0x92, 0x92, 0x93}
+	d := NewDecoder(bufio.NewReader(bytes.NewReader(data)), nil)
+	call, err := d.ReadCall()
+	assert.Nil(t, err)
+	assert.Equal(t, "add2", call.Method)
+	assert.Equal(t, 2, len(call.Args))
+}
+
+func TestCallTruncated(t *testing.T) {
+	// two arguments declared but only one sent
+	data := []byte{'H', 0x02, 0x00, 'C', 0x04, 'a', 'd', 'd', '2', 0x92, 0x92}
+	d := NewDecoder(bufio.NewReader(bytes.NewReader(data)), nil)
+	call, err := d.ReadCall()
+	assert.Nil(t, call)
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF), "%v", err)
+
+	// a huge argument count is rejected before reading the arguments
+	data = []byte{'H', 0x02, 0x00, 'C', 0x04, 'a', 'd', 'd', '2', 'I', 0x7f, 0xff, 0xff, 0xff, 0x92}
+	d = NewDecoder(bufio.NewReader(bytes.NewReader(data)), nil)
+	call, err = d.ReadCall()
+	assert.Nil(t, call)
+	assert.NotNil(t, err)
+}
+
+func TestReply(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	e := NewEncoder(buf, nil)
+	assert.Nil(t, e.WriteReply(int32(5)))
+	assert.Equal(t, []byte{'H', 0x02, 0x00, 'R', 0x95}, buf.Bytes())
+
+	d := NewDecoder(bufio.NewReader(buf), nil)
+	value, err := d.ReadReply()
+	assert.Nil(t, err)
+	assert.Equal(t, int32(5), value)
+
+	buf.Reset()
+	e.Reset(buf)
+	assert.Nil(t, e.WriteReply(nil))
+	d.Reset(bufio.NewReader(buf))
+	value, err = d.ReadReply()
+	assert.Nil(t, err)
+	assert.Nil(t, value)
+}
+
+func TestFault(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	e := NewEncoder(buf, nil)
+	assert.Nil(t, e.WriteFault(FaultServiceException, "File Not Found", nil))
+
+	expect := []byte{'H', 0x02, 0x00, 'F', 'H'}
+	expect = append(expect, encodeString("code")...)
+	expect = append(expect, encodeString("ServiceException")...)
+	expect = append(expect, encodeString("message")...)
+	expect = append(expect, encodeString("File Not Found")...)
+	expect = append(expect, 'Z')
+	assert.Equal(t, expect, buf.Bytes())
+
+	d := NewDecoder(bufio.NewReader(buf), nil)
+	value, err := d.ReadReply()
+	assert.Nil(t, value)
+	fault, ok := err.(*Fault)
+	assert.True(t, ok)
+	assert.Equal(t, FaultServiceException, fault.Code)
+	assert.Equal(t, "File Not Found", fault.Message)
+	assert.Nil(t, fault.Detail)
+}
+
+func TestFaultDetailRef(t *testing.T) {
+	detail := buildSingleCircularObject()
+	typMap, nameMap := ExtractTypeNameMap(detail)
+
+	buf := bytes.NewBuffer(nil)
+	e := NewEncoder(buf, nameMap)
+	assert.Nil(t, e.WriteFault(FaultServiceException, "failed", detail))
+
+	d := NewDecoder(bufio.NewReader(buf), typMap)
+	_, err := d.ReadReply()
+	fault, ok := err.(*Fault)
+	assert.True(t, ok)
+
+	c, ok := fault.Detail.(*circularT)
+	assert.True(t, ok)
+	assert.Equal(t, detail.Num, c.Num)
+	assert.True(t, AddrEqual(c, c.Previous))
+	assert.True(t, AddrEqual(c, c.Next))
+}
@@ -0,0 +1,138 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package hessian
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"time"
+)
+
+const (
+	_contentType = "x-application/hessian"
+
+	// max length of the response body kept in the error of an unexpected http status
+	_errorBodyMaxLen = 512
+)
+
+// Client a hessian http client, which calls the services exposed by
+// java HessianServlet or spring HessianServiceExporter.
+// A client can be used concurrently.
+type Client struct {
+	url        string
+	httpClient *http.Client
+	timeout    time.Duration
+	header     http.Header
+	typMap     map[string]reflect.Type
+	nameMap    map[string]string
+	codecOpts  []Option
+}
+
+// ClientOption option to create client
+type ClientOption func(c *Client)
+
+// WithHTTPClient set the http client to send requests, default http.DefaultClient
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTimeout set the timeout of each call, it overrides the timeout of the http client
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithHeader add a http header to each request
+func WithHeader(key, value string) ClientOption {
+	return func(c *Client) {
+		c.header.Add(key, value)
+	}
+}
+
+// WithCodecOptions set the options of the encoder and decoder,
+// e.g. WithProtocolVersion(ProtocolVersion1) to call hessian 1.0 services
+func WithCodecOptions(opts ...Option) ClientOption {
+	return func(c *Client) {
+		c.codecOpts = append(c.codecOpts, opts...)
+	}
+}
+
+// NewClient create a client for the service url,
+// the type map and name map are the same as those of NewSerializer,
+// which are read only and shared by the concurrent calls.
+func NewClient(url string, typMap map[string]reflect.Type, nameMap map[string]string, opts ...ClientOption) *Client {
+	c := &Client{
+		url:        url,
+		httpClient: http.DefaultClient,
+		header:     make(http.Header),
+		typMap:     typMap,
+		nameMap:    nameMap,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.timeout > 0 {
+		httpClient := *c.httpClient
+		httpClient.Timeout = c.timeout
+		c.httpClient = &httpClient
+	}
+	return c
+}
+
+// Invoke call the method with the arguments, and return the reply value.
+// A *Fault error is returned if the service replies a fault.
+func (c *Client) Invoke(method string, args ...interface{}) (interface{}, error) {
+	return c.InvokeContext(context.Background(), method, args...)
+}
+
+// InvokeContext call the method with the context
+func (c *Client) InvokeContext(ctx context.Context, method string, args ...interface{}) (interface{}, error) {
+	body := bytes.NewBuffer(nil)
+	if err := NewEncoder(body, c.nameMap, c.codecOpts...).WriteCall(method, args...); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.url, body)
+	if err != nil {
+		return nil, newCodecError("Invoke", err)
+	}
+	req = req.WithContext(ctx)
+	for k, v := range c.header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", _contentType)
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, newCodecError("Invoke", "call %s", method, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(io.LimitReader(res.Body, _errorBodyMaxLen))
+		return nil, newCodecError("Invoke", "call %s, http status %d: %s", method, res.StatusCode, msg)
+	}
+
+	return NewDecoder(bufio.NewReader(res.Body), c.typMap, c.codecOpts...).ReadReply()
+}
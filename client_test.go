@@ -0,0 +1,128 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package hessian
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// a java HessianServlet liked server, replying the sum of the point fields
+func newPointSumServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, _contentType, r.Header.Get("Content-Type"))
+
+		typMap, _ := ExtractTypeNameMap(P{})
+		call, err := NewDecoder(bufio.NewReader(r.Body), typMap).ReadCall()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		encoder := NewEncoder(w, nil)
+		switch call.Method {
+		case "sum":
+			p := call.Args[0].(*P)
+			encoder.WriteReply(int32(p.X + p.Y + p.Z))
+		case "token":
+			encoder.WriteReply(r.Header.Get("X-Token"))
+		case "sleep":
+			time.Sleep(300 * time.Millisecond)
+			encoder.WriteReply(nil)
+		default:
+			encoder.WriteFault(FaultNoSuchMethodException, "no method "+call.Method, nil)
+		}
+	}))
+}
+
+func TestClient(t *testing.T) {
+	server := newPointSumServer(t)
+	defer server.Close()
+
+	typMap, nameMap := ExtractTypeNameMap(P{})
+	client := NewClient(server.URL, typMap, nameMap, WithHeader("X-Token", "abc"))
+
+	sum, err := client.Invoke("sum", &P{X: 1, Y: 2, Z: 3})
+	assert.Nil(t, err)
+	assert.Equal(t, int32(6), sum)
+
+	token, err := client.Invoke("token")
+	assert.Nil(t, err)
+	assert.Equal(t, "abc", token)
+}
+
+func TestClientConcurrent(t *testing.T) {
+	server := newPointSumServer(t)
+	defer server.Close()
+
+	// the classes not in the name map are written by the go type names
+	typMap, _ := ExtractTypeNameMap(P{})
+	client := NewClient(server.URL, typMap, map[string]string{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sum, err := client.Invoke("sum", &P{X: i, Y: 1})
+			assert.Nil(t, err)
+			assert.Equal(t, int32(i+1), sum)
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestClientFault(t *testing.T) {
+	server := newPointSumServer(t)
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+	_, err := client.Invoke("multiply", int32(1), int32(2))
+	fault, ok := err.(*Fault)
+	assert.True(t, ok)
+	assert.Equal(t, FaultNoSuchMethodException, fault.Code)
+	assert.Equal(t, "no method multiply", fault.Message)
+}
+
+func TestClientTimeout(t *testing.T) {
+	server := newPointSumServer(t)
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil, WithHTTPClient(&http.Client{}), WithTimeout(50*time.Millisecond))
+	_, err := client.Invoke("sleep")
+	assert.NotNil(t, err)
+	_, ok := err.(*Fault)
+	assert.False(t, ok)
+}
+
+func TestClientHTTPStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Hessian Internal Server Error", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, nil, nil)
+	_, err := client.Invoke("sum")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "http status 500")
+}
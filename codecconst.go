@@ -30,6 +30,9 @@ const (
 
 var (
 	_buildInTypeNameMap = make(map[string]string)
+
+	// the java types which can be decoded without registering
+	_buildInTypeMap = make(map[string]reflect.Type)
 )
 
 func addBuildInNameType(i interface{}, convertName string) {
@@ -64,4 +67,10 @@ func init() {
 
 	// java: boolean
 	addBuildInNameType(true, "boolean")
+
+	// java: int[], the magnitude of java.math.BigInteger
+	_buildInTypeMap[_javaIntArrayName] = reflect.TypeOf([]int32{})
+
+	_buildInTypeMap[JavaBigDecimal] = _decimalType
+	_buildInTypeMap[JavaBigInteger] = _bigIntType
 }
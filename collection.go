@@ -0,0 +1,198 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+// Java collections.
+//
+// The lists, sets and maps of java.util are written by java as typed lists and typed maps
+// with the class names, e.g. java.util.LinkedList, java.util.TreeMap.
+// The class names of jdk collections are registered by default, without registering in the type map,
+// the lists and sets are decoded as []interface{}, and the maps are decoded as map[interface{}]interface{}.
+// A list can also be decoded into a go set, which is a map whose value type is struct{} or bool.
+//
+// A go set whose value type is struct{} is written as a list of the keys with the java set class name.
+// The plain go slices and maps are written as untyped lists and maps by default,
+// which are decoded as java.util.ArrayList and java.util.HashMap by java,
+// and the java class names can be specified by WithJavaListClass and WithJavaMapClass.
+//
+// -------------- Set example
+//
+// x71                # typed list of length 1
+//   x11 java.util.HashSet
+//   x03 foo          # "foo"
+
+package hessian
+
+import (
+	"reflect"
+)
+
+// the default java class names of go collections
+const (
+	JavaArrayList = "java.util.ArrayList"
+	JavaHashMap   = "java.util.HashMap"
+	JavaHashSet   = "java.util.HashSet"
+)
+
+var (
+	_interfaceMapType = reflect.TypeOf(map[interface{}]interface{}{})
+
+	_javaListClasses = []string{
+		"java.util.Collection",
+		"java.util.List",
+		JavaArrayList,
+		"java.util.LinkedList",
+		"java.util.Vector",
+		"java.util.Stack",
+		"java.util.ArrayDeque",
+		"java.util.concurrent.CopyOnWriteArrayList",
+		"java.util.Arrays$ArrayList",
+		"java.util.Collections$EmptyList",
+		"java.util.Collections$SingletonList",
+		"java.util.Collections$UnmodifiableCollection",
+		"java.util.Collections$UnmodifiableList",
+		"java.util.Collections$UnmodifiableRandomAccessList",
+		"java.util.Collections$SynchronizedCollection",
+		"java.util.Collections$SynchronizedList",
+		"java.util.Collections$SynchronizedRandomAccessList",
+	}
+
+	_javaSetClasses = []string{
+		"java.util.Set",
+		"java.util.SortedSet",
+		JavaHashSet,
+		"java.util.LinkedHashSet",
+		"java.util.TreeSet",
+		"java.util.concurrent.CopyOnWriteArraySet",
+		"java.util.concurrent.ConcurrentSkipListSet",
+		"java.util.Collections$EmptySet",
+		"java.util.Collections$SingletonSet",
+		"java.util.Collections$UnmodifiableSet",
+		"java.util.Collections$UnmodifiableSortedSet",
+		"java.util.Collections$SynchronizedSet",
+		"java.util.Collections$SynchronizedSortedSet",
+	}
+
+	_javaMapClasses = []string{
+		"java.util.Map",
+		"java.util.SortedMap",
+		JavaHashMap,
+		"java.util.LinkedHashMap",
+		"java.util.TreeMap",
+		"java.util.Hashtable",
+		"java.util.IdentityHashMap",
+		"java.util.WeakHashMap",
+		"java.util.concurrent.ConcurrentHashMap",
+		"java.util.concurrent.ConcurrentSkipListMap",
+		"java.util.Collections$EmptyMap",
+		"java.util.Collections$SingletonMap",
+		"java.util.Collections$UnmodifiableMap",
+		"java.util.Collections$UnmodifiableSortedMap",
+		"java.util.Collections$SynchronizedMap",
+		"java.util.Collections$SynchronizedSortedMap",
+	}
+)
+
+func init() {
+	for _, name := range _javaListClasses {
+		_buildInTypeMap[name] = _interfaceSliceType
+	}
+	for _, name := range _javaSetClasses {
+		_buildInTypeMap[name] = _interfaceSliceType
+	}
+	for _, name := range _javaMapClasses {
+		_buildInTypeMap[name] = _interfaceMapType
+	}
+}
+
+// isSetType check whether the map type is a go set, i.e. the value type is struct{} or bool
+func isSetType(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Map {
+		return false
+	}
+	elem := typ.Elem()
+	return elem.Kind() == reflect.Bool || (elem.Kind() == reflect.Struct && elem.NumField() == 0)
+}
+
+// write the go set as a list of the keys, the set name is looked up in the name map if it's empty
+func (e *Encoder) writeSet(vv reflect.Value, setName string) (int, error) {
+	if setName == "" {
+		var ok bool
+		if setName, ok = e.nameMap[vv.Type().Name()]; !ok {
+			setName = e.javaSetClass
+		}
+	}
+
+	keys := vv.MapKeys()
+	items := make([]interface{}, len(keys))
+	for i, k := range keys {
+		items[i] = k.Interface()
+	}
+	return e.writeTypedList(reflect.ValueOf(items), setName)
+}
+
+// read the list into the go set
+func (d *Decoder) readSet(dest reflect.Value, list interface{}) error {
+	items, _ := EnsureInterface(list, nil)
+	if items == nil {
+		return nil
+	}
+
+	vv := reflect.ValueOf(items)
+	if vv.Kind() != reflect.Slice {
+		return newCodecError("readSet", "expect list but get %v", vv.Type())
+	}
+
+	setTyp := UnpackPtrType(dest.Type())
+	set := reflect.MakeMapWithSize(setTyp, vv.Len())
+	elem := reflect.New(setTyp.Elem()).Elem()
+	if elem.Kind() == reflect.Bool {
+		elem.SetBool(true)
+	}
+	for i := 0; i < vv.Len(); i++ {
+		key, err := d.convertElem(setTyp.Key(), vv.Index(i).Interface())
+		if err != nil {
+			return newCodecError("readSet", err)
+		}
+		set.SetMapIndex(key, elem)
+	}
+	SetValue(dest, PackPtr(set))
+	return nil
+}
+
+// set the map entry, the key and value are converted to the types of the map
+func (o *options) setMapIndex(m reflect.Value, key, value interface{}) error {
+	k, err := o.convertElem(m.Type().Key(), key)
+	if err != nil {
+		return err
+	}
+	v, err := o.convertElem(m.Type().Elem(), value)
+	if err != nil {
+		return err
+	}
+	m.SetMapIndex(k, v)
+	return nil
+}
+
+// convertValue convert the decoded value to the type, e.g. the pointer of the decoded object to the struct,
+// the nil value is converted to the zero value.
+func convertValue(typ reflect.Type, in interface{}) reflect.Value {
+	v := EnsureRawValue(in)
+	if v.IsValid() && v.Type().AssignableTo(typ) {
+		return v
+	}
+	dest := reflect.New(typ).Elem()
+	SetValue(dest, v)
+	return dest
+}
@@ -0,0 +1,171 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package hessian
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJavaCollectionClasses(t *testing.T) {
+	list := []interface{}{int32(1), "a"}
+	m := map[interface{}]interface{}{"a": int32(1), "b": nil}
+
+	for _, version := range []int{ProtocolVersion2, ProtocolVersion1} {
+		for _, name := range _javaListClasses {
+			bs, err := ToBytes(list, nil, WithProtocolVersion(version), WithJavaListClass(name))
+			assert.Nil(t, err)
+			decoded, err := ToObject(bs, nil)
+			assert.Nil(t, err, name)
+			assert.Equal(t, list, decoded, name)
+		}
+		for _, name := range _javaSetClasses {
+			bs, err := ToBytes(map[string]struct{}{"a": {}}, nil, WithProtocolVersion(version), WithJavaSetClass(name))
+			assert.Nil(t, err)
+			decoded, err := ToObject(bs, nil)
+			assert.Nil(t, err, name)
+			assert.Equal(t, []interface{}{"a"}, decoded, name)
+		}
+		for _, name := range _javaMapClasses {
+			bs, err := ToBytes(m, nil, WithProtocolVersion(version), WithJavaMapClass(name))
+			assert.Nil(t, err)
+			decoded, err := ToObject(bs, nil)
+			assert.Nil(t, err, name)
+			assert.Equal(t, m, decoded, name)
+		}
+	}
+
+	// the hash set written by java
+	data, _ := base64.StdEncoding.DecodeString("chFqYXZhLnV0aWwuSGFzaFNldAZjY2NkZGQGYWFhYmJi")
+	decoded, err := ToObject(data, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{"cccddd", "aaabbb"}, decoded)
+}
+
+func TestJavaCollectionClassOptions(t *testing.T) {
+	bs, err := ToBytes([]int32{1}, nil, WithJavaListClass("java.util.LinkedList"))
+	assert.Nil(t, err)
+	assert.Equal(t, append(append([]byte{0x71, 0x14}, "java.util.LinkedList"...), 0x91), bs)
+
+	bs, err = ToBytes(map[string]int32{"a": 1}, nil, WithJavaMapClass("java.util.TreeMap"))
+	assert.Nil(t, err)
+	assert.Equal(t, append(append([]byte{'M', 0x11}, "java.util.TreeMap"...), 0x01, 'a', 0x91, 'Z'), bs)
+
+	bs, err = ToBytes(map[string]struct{}{"foo": {}}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, append(append([]byte{0x71, 0x11}, JavaHashSet...), 0x03, 'f', 'o', 'o'), bs)
+
+	// the name map takes precedence
+	bs, err = ToBytes([]int32{1}, map[string]string{"[]int32": "[int"}, WithJavaListClass("java.util.LinkedList"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x71, 0x04, '[', 'i', 'n', 't', 0x91}, bs)
+}
+
+type setHolderT struct {
+	Tags  map[string]struct{}
+	Flags map[int32]struct{}
+}
+
+type boolSetHolderT struct {
+	Tags  map[string]bool
+	Flags map[int64]bool
+}
+
+type collectionFieldsT struct {
+	Names  []string
+	Scores map[string]int32
+	Attrs  map[string]interface{}
+	Others map[string]interface{}
+}
+
+func TestJavaCollectionFields(t *testing.T) {
+	v := &collectionFieldsT{
+		Names:  []string{"a", "b"},
+		Scores: map[string]int32{"a": 1},
+		Attrs:  map[string]interface{}{"a": "x", "null": nil},
+		Others: map[string]interface{}{"b": int32(2)},
+	}
+	typMap, nameMap := ExtractTypeNameMap(v)
+	opts := []Option{WithJavaListClass("java.util.LinkedList"), WithJavaMapClass("java.util.LinkedHashMap")}
+
+	for _, version := range []int{ProtocolVersion2, ProtocolVersion1} {
+		bs, err := ToBytes(v, nameMap, append(opts, WithProtocolVersion(version))...)
+		assert.Nil(t, err)
+		decoded, err := ToObject(bs, typMap)
+		assert.Nil(t, err)
+		assert.Equal(t, v, decoded)
+	}
+
+	set := &setHolderT{
+		Tags:  map[string]struct{}{"a": {}, "b": {}},
+		Flags: map[int32]struct{}{1: {}},
+	}
+	nameMap = map[string]string{"setHolderT": "test.SetHolder"}
+	for _, version := range []int{ProtocolVersion2, ProtocolVersion1} {
+		bs, err := ToBytes(set, nameMap, WithProtocolVersion(version))
+		assert.Nil(t, err)
+
+		decoded, err := ToObject(bs, map[string]reflect.Type{"test.SetHolder": reflect.TypeOf(setHolderT{})})
+		assert.Nil(t, err)
+		assert.Equal(t, set, decoded)
+
+		decoded, err = ToObject(bs, map[string]reflect.Type{"test.SetHolder": reflect.TypeOf(boolSetHolderT{})})
+		assert.Nil(t, err)
+		assert.Equal(t, &boolSetHolderT{
+			Tags:  map[string]bool{"a": true, "b": true},
+			Flags: map[int64]bool{1: true},
+		}, decoded)
+	}
+}
+
+type nullElementListT struct {
+	Names []*string
+	Items []interface{}
+	After string
+}
+
+func TestJavaListNullElement(t *testing.T) {
+	a, b := "a", "b"
+	v := &nullElementListT{
+		Names: []*string{&a, nil, &b},
+		Items: []interface{}{"a", nil, "b"},
+		After: "after",
+	}
+	typMap, nameMap := ExtractTypeNameMap(v)
+
+	for _, version := range []int{ProtocolVersion2, ProtocolVersion1} {
+		bs, err := ToBytes(v, nameMap, WithProtocolVersion(version), WithJavaListClass("java.util.LinkedList"))
+		assert.Nil(t, err)
+		decoded, err := ToObject(bs, typMap)
+		assert.Nil(t, err)
+		assert.Equal(t, v, decoded)
+	}
+
+	// the variable-length typed list written by java
+	data := append(append([]byte{0x55, 0x14}, "java.util.LinkedList"...), 0x01, 'a', 'N', 0x01, 'b', 'Z', 0x91)
+	d := NewDecoder(bufio.NewReader(bytes.NewReader(data)), nil)
+	var names []*string
+	assert.Nil(t, d.DecodeInto(&names))
+	assert.Equal(t, []*string{&a, nil, &b}, names)
+	next, err := d.ReadObject()
+	assert.Nil(t, err)
+	assert.Equal(t, int32(1), next)
+}
@@ -21,9 +21,11 @@
 // Date represented by a 64-bit long of milliseconds since Jan 1 1970 00:00H, UTC.
 //
 // -------------- Compact: date in minutes
-
+//
 // The second form contains a 32-bit int of minutes since Jan 1 1970 00:00H, UTC.
+// It's written only when the date is exact to the minute.
 //
+// The decoded date is in the local time zone, unless a location is set by WithDateLocation.
 //
 // -------------- Date Examples
 //
@@ -36,6 +38,7 @@ package hessian
 import (
 	"encoding/binary"
 	"io"
+	"math"
 	"reflect"
 	"time"
 	"unsafe"
@@ -43,43 +46,54 @@ import (
 
 const (
 	_dateMillisStartTag = byte(0x4a)
-	_dateSecondStartTag = byte(0x4b)
+	_dateMinuteStartTag = byte(0x4b)
 )
 
 var _zeroDate time.Time
 var _dateType = reflect.TypeOf(time.Now())
 
 func dateTag(tag byte) bool {
-	return tag == _dateMillisStartTag || tag == _dateSecondStartTag
+	return tag == _dateMillisStartTag || tag == _dateMinuteStartTag
+}
+
+// milliseconds since the epoch, without the overflow of UnixNano for the dates after year 2262
+func unixMillis(date time.Time) int64 {
+	return date.Unix()*int64(time.Second/time.Millisecond) + int64(date.Nanosecond())/int64(time.Millisecond)
+}
+
+func millisTime(millis int64) time.Time {
+	perSecond := int64(time.Second / time.Millisecond)
+	return time.Unix(millis/perSecond, millis%perSecond*int64(time.Millisecond))
 }
 
 func encodeDate(date time.Time) []byte {
 	if date.IsZero() {
 		return []byte{_nilTag}
 	}
-	if date.UnixNano()%int64(time.Second) > 0 {
-		value := date.UnixNano() / int64(time.Millisecond)
 
-		// 8 octet longs
+	millis := unixMillis(date)
+	if minutes := millis / int64(time.Minute/time.Millisecond); millis%int64(time.Minute/time.Millisecond) == 0 &&
+		minutes >= math.MinInt32 && minutes <= math.MaxInt32 {
+		// 4 octet minutes
 		return []byte{
-			_dateMillisStartTag,
-			byte(value >> 56),
-			byte(value >> 48),
-			byte(value >> 40),
-			byte(value >> 32),
-			byte(value >> 24),
-			byte(value >> 16),
-			byte(value >> 8),
-			byte(value)}
+			_dateMinuteStartTag,
+			byte(minutes >> 24),
+			byte(minutes >> 16),
+			byte(minutes >> 8),
+			byte(minutes)}
 	}
 
-	value := date.Unix()
+	// 8 octet milliseconds
 	return []byte{
-		_dateSecondStartTag,
-		byte(value >> 24),
-		byte(value >> 16),
-		byte(value >> 8),
-		byte(value)}
+		_dateMillisStartTag,
+		byte(millis >> 56),
+		byte(millis >> 48),
+		byte(millis >> 40),
+		byte(millis >> 32),
+		byte(millis >> 24),
+		byte(millis >> 16),
+		byte(millis >> 8),
+		byte(millis)}
 }
 
 func decodeDate(reader ByteRuneReader) (time.Time, error) {
@@ -102,8 +116,8 @@ func decodeDateValue(reader ByteRuneReader, flag int32) (time.Time, error) {
 		by := []byte{bf[0], bf[1], bf[2], bf[3], bf[4], bf[5], bf[6], bf[7]}
 		u64 := binary.BigEndian.Uint64(by)
 		i64 := *(*int64)(unsafe.Pointer(&u64))
-		return time.Unix(0, i64*int64(time.Millisecond)), nil
-	case _dateSecondStartTag:
+		return millisTime(i64), nil
+	case _dateMinuteStartTag:
 		buf, err := readBytes(reader, 4)
 		if err != nil {
 			return _zeroDate, err
@@ -111,7 +125,7 @@ func decodeDateValue(reader ByteRuneReader, flag int32) (time.Time, error) {
 		by := []byte{buf[0], buf[1], buf[2], buf[3]}
 		u32 := binary.BigEndian.Uint32(by)
 		i32 := *(*int32)(unsafe.Pointer(&u32))
-		return time.Unix(int64(i32), 0), nil
+		return time.Unix(int64(i32)*int64(time.Minute/time.Second), 0), nil
 	}
 
 	return _zeroDate, newCodecError("decodeDateValue", "error date tag: 0x%x", tag)
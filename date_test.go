@@ -41,8 +41,20 @@ func TestDate(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, (date.UnixNano()/int64(time.Millisecond))*int64(time.Millisecond), d.UnixNano())
 
+	// not exact to the minute
 	nano := date.UnixNano()
 	date = time.Unix(nano/int64(time.Second), 0)
+	if date.Second() == 0 {
+		date = date.Add(time.Second)
+	}
+	bt = encodeDate(date)
+	assert.Equal(t, 9, len(bt))
+	reader = bufio.NewReader(bytes.NewReader(bt))
+	d, err = decodeDate(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, date.UnixNano(), d.UnixNano())
+
+	date = date.Truncate(time.Minute)
 	bt = encodeDate(date)
 	assert.Equal(t, 5, len(bt))
 	reader = bufio.NewReader(bytes.NewReader(bt))
@@ -51,6 +63,69 @@ func TestDate(t *testing.T) {
 	assert.Equal(t, date.UnixNano(), d.UnixNano())
 }
 
+func TestDateSpecExamples(t *testing.T) {
+	millis := []byte{0x4a, 0x00, 0x00, 0x00, 0xd0, 0x4b, 0x92, 0x84, 0xb8}
+	minutes := []byte{0x4b, 0x00, 0xe3, 0x83, 0x8f}
+
+	date := time.Date(1998, time.May, 8, 9, 51, 31, 0, time.UTC)
+	assert.Equal(t, millis, encodeDate(date))
+	d, err := decodeDate(bufio.NewReader(bytes.NewReader(millis)))
+	assert.Nil(t, err)
+	assert.True(t, date.Equal(d))
+
+	date = time.Date(1998, time.May, 8, 9, 51, 0, 0, time.UTC)
+	assert.Equal(t, minutes, encodeDate(date))
+	d, err = decodeDate(bufio.NewReader(bytes.NewReader(minutes)))
+	assert.Nil(t, err)
+	assert.True(t, date.Equal(d))
+
+	// minutes out of int32 are written in milliseconds
+	date = time.Date(3000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, _dateMinuteStartTag, encodeDate(date)[0])
+	date = time.Date(9999, time.January, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, _dateMillisStartTag, encodeDate(date)[0])
+}
+
+func TestDateLocation(t *testing.T) {
+	date := time.Date(1998, time.May, 8, 9, 51, 31, 0, time.UTC)
+	bt, err := ToBytes(date, nil)
+	assert.Nil(t, err)
+
+	decoded, err := ToObject(bt, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Local, decoded.(time.Time).Location())
+
+	decoded, err = ToObject(bt, nil, WithDateLocation(time.UTC))
+	assert.Nil(t, err)
+	assert.Equal(t, date, decoded)
+
+	loc := time.FixedZone("UTC+8", 8*3600)
+	decoded, err = ToObject(bt, nil, WithDateLocation(loc))
+	assert.Nil(t, err)
+	assert.Equal(t, date.In(loc), decoded)
+}
+
+func TestDateNotRef(t *testing.T) {
+	type S struct {
+		D1 time.Time
+		D2 *time.Time
+		P  *P
+		Q  *P
+	}
+	date := time.Date(1998, time.May, 8, 9, 51, 31, 0, time.UTC)
+	p := &P{X: 1}
+	s := &S{D1: date, D2: &date, P: p, Q: p}
+
+	typeMap, nameMap := ExtractTypeNameMap(s)
+	bt, err := ToBytes(s, nameMap)
+	assert.Nil(t, err)
+
+	decoded, err := ToObject(bt, typeMap, WithDateLocation(time.UTC))
+	assert.Nil(t, err)
+	assert.Equal(t, s, decoded)
+	assert.True(t, decoded.(*S).P == decoded.(*S).Q)
+}
+
 func TestDateInStruct(t *testing.T) {
 	type S struct {
 		D time.Time
@@ -0,0 +1,313 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+// java.math.BigDecimal and java.math.BigInteger.
+//
+// A java.math.BigDecimal is written by java as an object with the single field "value",
+// which is the string of the decimal, and it's mapped to Decimal.
+//
+// A java.math.BigInteger is written by java as an object with the fields of the class,
+// the sign is the field "signum", and the magnitude is the field "mag" which is an int array in big-endian order.
+// It's mapped to *big.Int.
+//
+// -------------- BigDecimal example
+//
+// C                         # class definition #0
+//   x14 java.math.BigDecimal
+//   x91                     # one field
+//   x05 value               # the string of the decimal
+//
+// x60                       # object #0 (class def #0)
+//   x05 12.34               # 12.34
+//
+// -------------- BigInteger example
+//
+// C                         # class definition #0
+//   x14 java.math.BigInteger
+//   x96                     # six fields
+//   x06 signum
+//   x08 bitCount
+//   x09 bitLength
+//   x0c lowestSetBit
+//   x12 firstNonzeroIntNum
+//   x03 mag
+//
+// x60                       # object #0 (class def #0)
+//   x91                     # signum = 1
+//   x90                     # bitCount, not computed
+//   x90                     # bitLength, not computed
+//   x90                     # lowestSetBit, not computed
+//   x90                     # firstNonzeroIntNum, not computed
+//   x71 x04 [int            # mag, int[] of length 1
+//     x9c                   # 12
+
+package hessian
+
+import (
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	// JavaBigDecimal the java class name of big decimal
+	JavaBigDecimal = "java.math.BigDecimal"
+
+	// JavaBigInteger the java class name of big integer
+	JavaBigInteger = "java.math.BigInteger"
+
+	_javaIntArrayName = "[int"
+)
+
+var (
+	_decimalType = reflect.TypeOf(Decimal{})
+	_bigIntType  = reflect.TypeOf(big.Int{})
+
+	_bigDecimalFields = []string{"value"}
+	_bigIntegerFields = []string{"signum", "bitCount", "bitLength", "lowestSetBit", "firstNonzeroIntNum", "mag"}
+)
+
+// Decimal java.math.BigDecimal, the value is Unscaled * 10^(-Scale)
+type Decimal struct {
+	Unscaled *big.Int
+	Scale    int32
+}
+
+// NewDecimal create a decimal whose value is unscaled * 10^(-scale)
+func NewDecimal(unscaled *big.Int, scale int32) *Decimal {
+	return &Decimal{Unscaled: unscaled, Scale: scale}
+}
+
+// ParseDecimal parse the string representation of java.math.BigDecimal, e.g. "12.34", "-1E+3"
+func ParseDecimal(s string) (*Decimal, error) {
+	str := s
+	exp := int64(0)
+	if i := strings.IndexAny(str, "eE"); i >= 0 {
+		var err error
+		if exp, err = strconv.ParseInt(str[i+1:], 10, 32); err != nil {
+			return nil, newCodecError("ParseDecimal", "invalid decimal: %s", s)
+		}
+		str = str[:i]
+	}
+
+	scale := int64(0)
+	if i := strings.IndexByte(str, '.'); i >= 0 {
+		scale = int64(len(str) - i - 1)
+		str = str[:i] + str[i+1:]
+	}
+
+	digits := strings.TrimLeft(str, "+-")
+	if digits == "" || len(str)-len(digits) > 1 || strings.IndexFunc(digits, func(r rune) bool { return r < '0' || r > '9' }) >= 0 {
+		return nil, newCodecError("ParseDecimal", "invalid decimal: %s", s)
+	}
+
+	unscaled, _ := new(big.Int).SetString(str, 10)
+	return NewDecimal(unscaled, int32(scale-exp)), nil
+}
+
+// String return the same string as java.math.BigDecimal.toString()
+func (d Decimal) String() string {
+	unscaled := d.Unscaled
+	if unscaled == nil {
+		unscaled = new(big.Int)
+	}
+
+	coeff := new(big.Int).Abs(unscaled).String()
+	sign := ""
+	if unscaled.Sign() < 0 {
+		sign = "-"
+	}
+	if d.Scale == 0 {
+		return sign + coeff
+	}
+
+	scale := int(d.Scale)
+	adjusted := -scale + len(coeff) - 1
+
+	// plain notation
+	if scale > 0 && adjusted >= -6 {
+		if pad := scale - len(coeff); pad >= 0 {
+			return sign + "0." + strings.Repeat("0", pad) + coeff
+		}
+		return sign + coeff[:len(coeff)-scale] + "." + coeff[len(coeff)-scale:]
+	}
+
+	// scientific notation
+	s := sign + coeff[:1]
+	if len(coeff) > 1 {
+		s += "." + coeff[1:]
+	}
+	if adjusted != 0 {
+		s += "E"
+		if adjusted > 0 {
+			s += "+"
+		}
+		s += strconv.Itoa(adjusted)
+	}
+	return s
+}
+
+// Rat return the value of the decimal as a rational number
+func (d Decimal) Rat() *big.Rat {
+	r := new(big.Rat)
+	if d.Unscaled != nil {
+		r.SetInt(d.Unscaled)
+	}
+	pow := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(absInt32(d.Scale))), nil)
+	if d.Scale > 0 {
+		return r.Quo(r, new(big.Rat).SetInt(pow))
+	}
+	return r.Mul(r, new(big.Rat).SetInt(pow))
+}
+
+func absInt32(i int32) int64 {
+	if i < 0 {
+		return -int64(i)
+	}
+	return int64(i)
+}
+
+// the words of the magnitude of java.math.BigInteger in big-endian order
+func bigIntegerMag(i *big.Int) []int32 {
+	bs := new(big.Int).Abs(i).Bytes()
+	mag := make([]int32, (len(bs)+3)/4)
+	for j := len(bs) - 1; j >= 0; j-- {
+		n := len(bs) - 1 - j
+		mag[len(mag)-1-n/4] |= int32(bs[j]) << (8 * uint(n%4))
+	}
+	return mag
+}
+
+func newBigInteger(signum int32, mag []int32) *big.Int {
+	bs := make([]byte, 4*len(mag))
+	for j, w := range mag {
+		bs[4*j], bs[4*j+1], bs[4*j+2], bs[4*j+3] = byte(w>>24), byte(w>>16), byte(w>>8), byte(w)
+	}
+	i := new(big.Int).SetBytes(bs)
+	if signum < 0 {
+		i.Neg(i)
+	}
+	return i
+}
+
+func isBigNumberType(typ reflect.Type) bool {
+	return typ == _decimalType || typ == _bigIntType
+}
+
+func (e *Encoder) writeDecimal(source interface{}, d Decimal) (int, error) {
+	if n, ok := e.checkEncodeRefMap(reflect.ValueOf(source)); ok {
+		return e.writeRef(n)
+	}
+
+	return e.writeClassObject(JavaBigDecimal, _bigDecimalFields, func(string) error {
+		_, err := e.writeString(d.String())
+		return err
+	})
+}
+
+func (e *Encoder) writeBigInteger(source interface{}, i *big.Int) (int, error) {
+	if n, ok := e.checkEncodeRefMap(reflect.ValueOf(source)); ok {
+		return e.writeRef(n)
+	}
+
+	return e.writeClassObject(JavaBigInteger, _bigIntegerFields, func(fld string) error {
+		var err error
+		switch fld {
+		case "signum":
+			_, err = e.writeInt(int32(i.Sign()))
+		case "mag":
+			e.addRefPlaceholder()
+			_, err = e.writeTypedList(reflect.ValueOf(bigIntegerMag(i)), _javaIntArrayName)
+		default:
+			// the cached values which are computed when needed
+			_, err = e.writeInt(0)
+		}
+		return err
+	})
+}
+
+// read the big number after the object tag
+func (d *Decoder) readBigNumber(typ reflect.Type, cls ClassDef) (reflect.Value, error) {
+	vv := reflect.New(typ)
+	d.addDecoderRef(vv)
+
+	fields := make(map[string]interface{}, len(cls.FieldName))
+	for _, fldName := range cls.FieldName {
+		v, err := d.ReadObject()
+		if err != nil {
+			return _zeroValue, newCodecError("readBigNumber", "failed to decode field '%s'", fldName, err)
+		}
+		fields[fldName] = v
+	}
+	return vv, setBigNumber(vv, fields)
+}
+
+// read the big number after the type of hessian 1.0 map
+func (d *Decoder) readBigNumber1(typ reflect.Type, tag byte) (reflect.Value, error) {
+	vv := reflect.New(typ)
+	d.addDecoderRef(vv)
+
+	fields := make(map[string]interface{})
+	for tag != _end1Flag {
+		fldName, err := d.readString(int32(tag))
+		if err != nil {
+			return _zeroValue, newCodecError("readBigNumber1", "read field name", err)
+		}
+		if fields[fldName], err = EnsureInterface(d.readData1(_tagRead)); err != nil {
+			return _zeroValue, newCodecError("readBigNumber1", "failed to decode field '%s'", fldName, err)
+		}
+
+		if tag, err = d.readTag(); err != nil {
+			return _zeroValue, newCodecError("readBigNumber1", err)
+		}
+	}
+	return vv, setBigNumber(vv, fields)
+}
+
+// set the big number from the decoded fields
+func setBigNumber(vv reflect.Value, fields map[string]interface{}) error {
+	// the string value
+	if s, ok := fields["value"].(string); ok {
+		d, err := ParseDecimal(s)
+		if err != nil {
+			return err
+		}
+		if vv.Type().Elem() == _decimalType {
+			vv.Elem().Set(reflect.ValueOf(*d))
+		} else {
+			vv.Interface().(*big.Int).Set(d.Unscaled)
+		}
+		return nil
+	}
+
+	if vv.Type().Elem() == _decimalType {
+		return newCodecError("setBigNumber", "no value of %s", JavaBigDecimal)
+	}
+
+	signum, _ := fields["signum"].(int32)
+	var mag []int32
+	switch m := fields["mag"].(type) {
+	case []int32:
+		mag = m
+	case []interface{}:
+		mag = make([]int32, len(m))
+		for j, w := range m {
+			mag[j], _ = w.(int32)
+		}
+	}
+	vv.Interface().(*big.Int).Set(newBigInteger(signum, mag))
+	return nil
+}
@@ -0,0 +1,127 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package hessian
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecimalString(t *testing.T) {
+	for _, c := range []struct {
+		s        string
+		unscaled int64
+		scale    int32
+	}{
+		{"0", 0, 0},
+		{"12.34", 1234, 2},
+		{"-0.001", -1, 3},
+		{"123.4500", 1234500, 4},
+		{"0.000001", 1, 6},
+		{"1E-7", 1, 7},
+		{"1.23E-10", 123, 12},
+		{"1E+3", 1, -3},
+		{"-1.5E+5", -15, -4},
+	} {
+		d, err := ParseDecimal(c.s)
+		assert.Nil(t, err)
+		assert.Equal(t, NewDecimal(big.NewInt(c.unscaled), c.scale), d, c.s)
+		assert.Equal(t, c.s, d.String())
+	}
+
+	d, err := ParseDecimal("+.5e2")
+	assert.Nil(t, err)
+	assert.Equal(t, NewDecimal(big.NewInt(5), -1), d)
+	assert.Equal(t, big.NewRat(50, 1), d.Rat())
+
+	for _, s := range []string{"", "+", "abc", "1.2.3", "--1", "1e", "1e+"} {
+		_, err = ParseDecimal(s)
+		assert.NotNil(t, err, s)
+	}
+}
+
+func TestDecimalEncode(t *testing.T) {
+	bs, err := ToBytes(NewDecimal(big.NewInt(1234), 2), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, append(append([]byte{'C', 0x14}, JavaBigDecimal...),
+		0x91, 0x05, 'v', 'a', 'l', 'u', 'e',
+		0x60, 0x05, '1', '2', '.', '3', '4',
+	), bs)
+
+	bs, err = ToBytes(big.NewInt(12), nil)
+	assert.Nil(t, err)
+	expect := bytes.NewBuffer(nil)
+	expect.Write([]byte{'C', 0x14})
+	expect.WriteString(JavaBigInteger)
+	expect.WriteByte(0x96)
+	for _, fld := range _bigIntegerFields {
+		expect.WriteByte(byte(len(fld)))
+		expect.WriteString(fld)
+	}
+	expect.Write([]byte{0x60, 0x91, 0x90, 0x90, 0x90, 0x90, 0x71, 0x04, '[', 'i', 'n', 't', 0x9c})
+	assert.Equal(t, expect.Bytes(), bs)
+}
+
+func TestBigIntegerMag(t *testing.T) {
+	for _, s := range []string{"0", "1", "-1", "4294967295", "4294967296", "-123456789012345678901234567890"} {
+		i, _ := new(big.Int).SetString(s, 10)
+		assert.Equal(t, 0, i.Cmp(newBigInteger(int32(i.Sign()), bigIntegerMag(i))), s)
+	}
+	assert.Equal(t, []int32{1, 0}, bigIntegerMag(big.NewInt(1<<32)))
+	assert.Equal(t, []int32{-1}, bigIntegerMag(big.NewInt(0xffffffff)))
+}
+
+func TestBigNumberRoundTrip(t *testing.T) {
+	type moneyT struct {
+		Amount  Decimal
+		Price   *Decimal
+		Fee     *Decimal
+		Count   *big.Int
+		Balance *big.Int
+		Zero    *big.Int
+	}
+	count, _ := new(big.Int).SetString("-123456789012345678901234567890", 10)
+	price, _ := ParseDecimal("99.990")
+	v := &moneyT{
+		Amount:  *NewDecimal(big.NewInt(-1), 3),
+		Price:   price,
+		Fee:     price,
+		Count:   count,
+		Balance: big.NewInt(1 << 40),
+		Zero:    new(big.Int),
+	}
+	typMap, nameMap := ExtractTypeNameMap(v)
+
+	for _, version := range []int{ProtocolVersion2, ProtocolVersion1} {
+		bs, err := ToBytes(v, nameMap, WithProtocolVersion(version))
+		assert.Nil(t, err)
+		decoded, err := ToObject(bs, typMap, WithProtocolVersion(version))
+		assert.Nil(t, err)
+		assert.Equal(t, v, decoded)
+
+		// untyped values are decoded without registering
+		for _, value := range []interface{}{price, count} {
+			bs, err = ToBytes(value, nil, WithProtocolVersion(version))
+			assert.Nil(t, err)
+			decoded, err = ToObject(bs, nil, WithProtocolVersion(version))
+			assert.Nil(t, err)
+			assert.Equal(t, value, decoded)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package hessian
+
+import (
+	"bufio"
+	"bytes"
+	"reflect"
+)
+
+// DecodeInto read the next value into the value pointed by ptr, the static type of ptr is used to decode
+// the object, list and map, including their elements, even if the class name in the stream is not registered.
+func (d *Decoder) DecodeInto(ptr interface{}) error {
+	v := reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return newCodecError("DecodeInto", "the destination must be a non-nil pointer, but get %T", ptr)
+	}
+
+	dest := v.Elem()
+	if err := d.readField(dest.Type().String(), dest); err != nil {
+		return newCodecError("DecodeInto", err)
+	}
+	return nil
+}
+
+// Unmarshal [NO-CACHE API] deserialize bytes into the value pointed by ptr, see Decoder.DecodeInto
+func Unmarshal(data []byte, ptr interface{}, opts ...Option) error {
+	d := NewDecoder(bufio.NewReader(bytes.NewReader(data)), nil, opts...)
+	return d.DecodeInto(ptr)
+}
+
+// readDataAs read the data with the expected type, e.g. the type of the destination or the element,
+// the flag is the tag already read or _tagRead.
+func (d *Decoder) readDataAs(typ reflect.Type, flag int32) (interface{}, error) {
+	if ok, v, err := d.readUnmarshalerAs(typ, flag); ok {
+		return v, err
+	}
+
+	d.expectType = typ
+	defer d.takeExpectType()
+
+	if d.isHessian1() {
+		return d.readData1(flag)
+	}
+	return d.readData(flag)
+}
+
+// takeExpectType take the expected type of the object, list or map being read,
+// which must be taken before reading the nested values.
+func (d *Decoder) takeExpectType() reflect.Type {
+	typ := d.expectType
+	d.expectType = nil
+	return typ
+}
+
+// expectKind return the expected type if its kind (or the kind of its element type for pointer) is one of the kinds
+func expectKind(typ reflect.Type, kinds ...reflect.Kind) (reflect.Type, bool) {
+	if typ == nil {
+		return nil, false
+	}
+	typ = UnpackPtrType(typ)
+	for _, kind := range kinds {
+		if typ.Kind() == kind {
+			return typ, true
+		}
+	}
+	return nil, false
+}
+
+// the element type of the expected list, or nil if it's not a list
+func expectElem(typ reflect.Type) reflect.Type {
+	if typ, ok := expectKind(typ, reflect.Slice, reflect.Array); ok {
+		return typ.Elem()
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package hessian
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type unmarshalItemT struct {
+	Name  string
+	Count int32
+}
+
+type unmarshalOrderT struct {
+	ID    int64
+	Items []*unmarshalItemT
+	Index map[string]unmarshalItemT
+}
+
+func TestUnmarshal(t *testing.T) {
+	item := &unmarshalItemT{Name: "book", Count: 2}
+	v := &unmarshalOrderT{
+		ID:    1,
+		Items: []*unmarshalItemT{item, item},
+		Index: map[string]unmarshalItemT{"pen": {Name: "pen", Count: 1}},
+	}
+	// the class names are not registered in the decoder
+	nameMap := map[string]string{"unmarshalOrderT": "test.Order", "unmarshalItemT": "test.Item"}
+
+	for _, version := range []int{ProtocolVersion2, ProtocolVersion1} {
+		bs, err := ToBytes(v, nameMap, WithProtocolVersion(version))
+		assert.Nil(t, err)
+
+		var order unmarshalOrderT
+		assert.Nil(t, Unmarshal(bs, &order, WithProtocolVersion(version)))
+		assert.Equal(t, *v, order)
+		assert.True(t, order.Items[0] == order.Items[1])
+
+		var pOrder *unmarshalOrderT
+		assert.Nil(t, Unmarshal(bs, &pOrder, WithProtocolVersion(version)))
+		assert.Equal(t, v, pOrder)
+
+		bs, err = ToBytes(v.Items, nameMap, WithProtocolVersion(version), WithJavaListClass("java.util.ArrayList"))
+		assert.Nil(t, err)
+		var items []unmarshalItemT
+		assert.Nil(t, Unmarshal(bs, &items, WithProtocolVersion(version)))
+		assert.Equal(t, []unmarshalItemT{*item, *item}, items)
+
+		bs, err = ToBytes(v.Index, nameMap, WithProtocolVersion(version))
+		assert.Nil(t, err)
+		var index map[string]*unmarshalItemT
+		assert.Nil(t, Unmarshal(bs, &index, WithProtocolVersion(version)))
+		assert.Equal(t, map[string]*unmarshalItemT{"pen": {Name: "pen", Count: 1}}, index)
+	}
+}
+
+func TestDecodeInto(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	e := NewEncoder(buf, map[string]string{"unmarshalItemT": "test.Item"})
+	assert.Nil(t, e.WriteObject(&unmarshalItemT{Name: "a", Count: 1}))
+	assert.Nil(t, e.WriteObject(&unmarshalItemT{Name: "b", Count: 2}))
+	assert.Nil(t, e.WriteObject("c"))
+	assert.Nil(t, e.WriteObject(int32(3)))
+
+	d := NewDecoder(bufio.NewReader(buf), nil)
+	var a, b unmarshalItemT
+	assert.Nil(t, d.DecodeInto(&a))
+	assert.Nil(t, d.DecodeInto(&b))
+	assert.Equal(t, unmarshalItemT{Name: "a", Count: 1}, a)
+	assert.Equal(t, unmarshalItemT{Name: "b", Count: 2}, b)
+
+	var s string
+	assert.Nil(t, d.DecodeInto(&s))
+	assert.Equal(t, "c", s)
+
+	var i interface{}
+	assert.Nil(t, d.DecodeInto(&i))
+	assert.Equal(t, int32(3), i)
+
+	assert.NotNil(t, d.DecodeInto(a))
+	assert.NotNil(t, d.DecodeInto((*unmarshalItemT)(nil)))
+}
@@ -55,15 +55,23 @@ type Decoder struct {
 	typList    []string
 	refList    []reflect.Value
 	clsDefList []ClassDef
+	options
+
+	// the protocol version of current stream, detected at the first read if not specified
+	protocolVersion int
+
+	// the expected type of the next object, list or map, see DecodeInto
+	expectType reflect.Type
 }
 
 //NewDecoder new
-func NewDecoder(r ByteRuneReader, typ map[string]reflect.Type) *Decoder {
+func NewDecoder(r ByteRuneReader, typ map[string]reflect.Type, opts ...Option) *Decoder {
 	if typ == nil {
 		typ = make(map[string]reflect.Type, 11)
 	}
 	decode := &Decoder{
-		typMap: typ,
+		typMap:  typ,
+		options: newOptions(opts),
 	}
 	if r != nil {
 		decode.Reset(r)
@@ -74,6 +82,13 @@ func NewDecoder(r ByteRuneReader, typ map[string]reflect.Type) *Decoder {
 //Reset reset
 func (d *Decoder) Reset(r ByteRuneReader) {
 	d.reader = r
+	d.resetRefs()
+	d.protocolVersion = d.version
+	d.expectType = nil
+}
+
+// resetRefs clear the types, class definitions and refs read
+func (d *Decoder) resetRefs() {
 	d.typList = make([]string, 0, 11)
 	d.clsDefList = make([]ClassDef, 0, 11)
 	d.refList = make([]reflect.Value, 0, 11)
@@ -94,10 +109,28 @@ func (d *Decoder) RegisterVal(key string, val interface{}) {
 	d.typMap[key] = reflect.TypeOf(val)
 }
 
+// findType find the type of the name in the type map, or in the build-in types if not registered
+func (d *Decoder) findType(name string) (reflect.Type, bool) {
+	if typ, ok := d.typMap[name]; ok {
+		return typ, true
+	}
+	typ, ok := _buildInTypeMap[name]
+	return typ, ok
+}
+
 func (d *Decoder) readTag() (byte, error) {
 	return readTag(d.reader)
 }
 
+// unreadTag unread the tag just read, the reader must be an io.ByteScanner (e.g. *bufio.Reader).
+func (d *Decoder) unreadTag() error {
+	s, ok := d.reader.(io.ByteScanner)
+	if !ok {
+		return newCodecError("unreadTag", "reader can't unread byte")
+	}
+	return s.UnreadByte()
+}
+
 func (d *Decoder) readBytes(size int) ([]byte, error) {
 	return readBytes(d.reader, size)
 }
@@ -140,14 +173,33 @@ func (d *Decoder) readDouble(flag int32) (float64, error) {
 }
 
 func (d *Decoder) readString(flag int32) (string, error) {
+	if d.isHessian1() {
+		return decodeString1Value(d.reader, flag)
+	}
 	return decodeStringValue(d.reader, flag)
 }
 
 func (d *Decoder) readDate(flag int32) (time.Time, error) {
-	return decodeDateValue(d.reader, flag)
+	var (
+		date time.Time
+		err  error
+	)
+	if d.isHessian1() {
+		date, err = decodeDate1Value(d.reader, flag)
+	} else {
+		date, err = decodeDateValue(d.reader, flag)
+	}
+	if err == nil && d.dateLocation != nil {
+		date = date.In(d.dateLocation)
+	}
+	return date, err
 }
 
 func (d *Decoder) readStruct() (interface{}, error) {
+	if d.isHessian1() {
+		return d.readStruct1()
+	}
+
 	tag, err := d.readTag()
 	if err != nil {
 		hlog.Debugf("reading tag err:%v", err)
@@ -176,7 +228,15 @@ func (d *Decoder) readStruct() (interface{}, error) {
 
 //ReadData read object
 func (d *Decoder) ReadData() (interface{}, error) {
-	tag, err := d.readTag()
+	if d.isHessian1() {
+		return d.readData1(_tagRead)
+	}
+	return d.readData(_tagRead)
+}
+
+// readData read hessian 2.0 object, the flag is the tag already read or _tagRead
+func (d *Decoder) readData(flag int32) (interface{}, error) {
+	tag, err := getTag(d.reader, flag)
 	if err != nil {
 		hlog.Debugf("reading tag err:%v", err)
 		return nil, nil //ignore
@@ -217,6 +277,11 @@ func (d *Decoder) ReadData() (interface{}, error) {
 		return d.readTagObject()
 	case typedListTag(tag) || untypedListTag(tag):
 		return d.ReadList(int32(tag))
+	case tag == _envelopeTag:
+		if err = d.unwrapEnvelope(); err != nil {
+			return nil, newCodecError("readData", err)
+		}
+		return d.ReadData()
 	default:
 		return nil, newCodecError("readData", "unknown tag: 0x%x", tag)
 	}
@@ -0,0 +1,127 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+// Deflation envelope, compatible with java com.caucho.hessian.io.Deflation.
+//
+// The body of the envelope is the wrapped hessian content compressed by deflate in zlib format
+// (compress/flate data with the zlib header and checksum), which is the format of java DeflaterOutputStream.
+// There is no header or footer.
+//
+// -------------- Deflation example
+//
+// E                                  # envelope
+//   x1f com.caucho.hessian.io.Deflation
+//   x90                              # no headers
+//   x41 b1 b0 <deflated-data>        # non-final binary chunk
+//   B b1 b0 <deflated-data>          # final binary chunk
+//   x90                              # no footers
+//   Z
+//
+// The decoder unwraps the deflation envelope transparently, the unwrapped content is read
+// as if it's in place of the envelope, with the refs and class definitions reset,
+// for it's written by a new hessian output in java.
+
+package hessian
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"io"
+	"io/ioutil"
+)
+
+// DeflationEnvelope the envelope name of java com.caucho.hessian.io.Deflation
+const DeflationEnvelope = "com.caucho.hessian.io.Deflation"
+
+// Deflate create a deflation envelope of the hessian content
+func Deflate(content []byte) (*Envelope, error) {
+	buf := bytes.NewBuffer(nil)
+	w := zlib.NewWriter(buf)
+	if _, err := w.Write(content); err != nil {
+		return nil, newCodecError("Deflate", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, newCodecError("Deflate", err)
+	}
+	return &Envelope{Method: DeflationEnvelope, Body: buf.Bytes()}, nil
+}
+
+// DefaultMaxInflateSize the default max size of the content inflated from the deflation envelope
+const DefaultMaxInflateSize = 64 * 1024 * 1024
+
+// Inflate return the hessian content wrapped in the deflation envelope,
+// an error is returned if the content is larger than DefaultMaxInflateSize.
+func Inflate(env *Envelope) ([]byte, error) {
+	return inflate(env, DefaultMaxInflateSize)
+}
+
+func inflate(env *Envelope, maxSize int) ([]byte, error) {
+	if env.Method != DeflationEnvelope {
+		return nil, newCodecError("Inflate", "unsupported envelope: %s", env.Method)
+	}
+	r, err := zlib.NewReader(bytes.NewReader(env.Body))
+	if err != nil {
+		return nil, newCodecError("Inflate", err)
+	}
+	defer r.Close()
+
+	content, err := ioutil.ReadAll(io.LimitReader(r, int64(maxSize)+1))
+	if err != nil {
+		return nil, newCodecError("Inflate", err)
+	}
+	if len(content) > maxSize {
+		return nil, newCodecError("Inflate", "content larger than %d", maxSize)
+	}
+	return content, nil
+}
+
+// WriteDeflation write the value in a deflation envelope
+func (e *Encoder) WriteDeflation(value interface{}) error {
+	buf := bytes.NewBuffer(nil)
+	inner := &Encoder{nameMap: e.nameMap, options: e.options}
+	inner.Reset(buf)
+	if _, err := inner.WriteData(value); err != nil {
+		return newCodecError("WriteDeflation", err)
+	}
+
+	env, err := Deflate(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	return e.WriteEnvelope(env)
+}
+
+// unwrapEnvelope read the envelope after the tag 'E', and put the unwrapped content in front of the reader.
+// The expected type and the protocol version are kept for the unwrapped content.
+func (d *Decoder) unwrapEnvelope() error {
+	typ := d.takeExpectType()
+	env, err := d.readEnvelope()
+	if err != nil {
+		return err
+	}
+	maxSize := d.maxInflateSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxInflateSize
+	}
+	content, err := inflate(env, maxSize)
+	if err != nil {
+		return err
+	}
+	d.reader = bufio.NewReader(io.MultiReader(bytes.NewReader(content), d.reader))
+	d.resetRefs()
+	d.expectType = typ
+	return nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package hessian
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeflation(t *testing.T) {
+	typMap, nameMap := ExtractTypeNameMap(P{})
+	p := &P{X: 1, Y: 2, Name: strings.Repeat("p", 1000)}
+
+	buf := bytes.NewBuffer(nil)
+	e := NewEncoder(buf, nameMap)
+	assert.Nil(t, e.WriteDeflation(p))
+	assert.Nil(t, e.WriteObject("after"))
+
+	bs := buf.Bytes()
+	assert.Equal(t, byte(_envelopeTag), bs[0])
+	assert.Equal(t, DeflationEnvelope, string(bs[2:2+len(DeflationEnvelope)]))
+	assert.True(t, len(bs) < len(p.Name))
+
+	d := NewDecoder(bufio.NewReader(buf), typMap)
+	v, err := d.ReadObject()
+	assert.Nil(t, err)
+	assert.Equal(t, p, v)
+	v, err = d.ReadObject()
+	assert.Nil(t, err)
+	assert.Equal(t, "after", v)
+}
+
+func TestDeflationLargeBody(t *testing.T) {
+	// random data can't be compressed, so that the body is written in binary chunks
+	data := make([]byte, _binaryChunkSize*3)
+	_, err := rand.Read(data)
+	assert.Nil(t, err)
+
+	buf := bytes.NewBuffer(nil)
+	assert.Nil(t, NewEncoder(buf, nil).WriteDeflation(data))
+	assert.Equal(t, byte(_binaryChunk), buf.Bytes()[len(DeflationEnvelope)+3])
+
+	v, err := NewDecoder(bufio.NewReader(buf), nil).ReadObject()
+	assert.Nil(t, err)
+	assert.Equal(t, data, v)
+}
+
+func TestDeflationCall(t *testing.T) {
+	content := bytes.NewBuffer(nil)
+	assert.Nil(t, NewEncoder(content, nil).WriteCall("add2", int32(2), int32(3)))
+
+	// skip the version header, which is written before the envelope
+	env, err := Deflate(content.Bytes()[3:])
+	assert.Nil(t, err)
+
+	buf := bytes.NewBuffer([]byte{_versionTag, _versionMajor, _versionMinor})
+	assert.Nil(t, NewEncoder(buf, nil).WriteEnvelope(env))
+
+	call, err := NewDecoder(bufio.NewReader(buf), nil).ReadCall()
+	assert.Nil(t, err)
+	assert.Equal(t, &Call{Method: "add2", Args: []interface{}{int32(2), int32(3)}}, call)
+}
+
+func TestInflateUnsupported(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	assert.Nil(t, NewEncoder(buf, nil).WriteEnvelope(&Envelope{Method: "Unknown", Body: []byte{1}}))
+
+	_, err := NewDecoder(bufio.NewReader(buf), nil).ReadObject()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "unsupported envelope: Unknown")
+}
+
+func TestDeflationExpectType(t *testing.T) {
+	_, nameMap := ExtractTypeNameMap(P{})
+	p := P{X: 1, Y: 2, Name: "point"}
+
+	// a list of one element, which is wrapped in the deflation envelope
+	buf := bytes.NewBuffer([]byte{_listFixedUntypedLenTagMin + 1})
+	assert.Nil(t, NewEncoder(buf, nameMap).WriteDeflation(p))
+
+	// the class isn't registered, the wrapped object is decoded by the element type of the destination
+	var decoded []P
+	assert.Nil(t, Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, []P{p}, decoded)
+}
+
+func TestInflateMaxSize(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	assert.Nil(t, NewEncoder(buf, nil).WriteDeflation(strings.Repeat("a", 1000)))
+	data := buf.Bytes()
+
+	_, err := NewDecoder(bufio.NewReader(bytes.NewReader(data)), nil, WithMaxInflateSize(100)).ReadObject()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "content larger than 100")
+
+	v, err := NewDecoder(bufio.NewReader(bytes.NewReader(data)), nil, WithMaxInflateSize(2000)).ReadObject()
+	assert.Nil(t, err)
+	assert.Equal(t, strings.Repeat("a", 1000), v)
+}
@@ -41,8 +41,14 @@
 // Doubles between -32768.0 and 32767.0 with no fractional component can be represented in three octets by casting the short value to a double.
 // 	value = (double) (256 * b1 + b0)
 //
-// ===> double float
-// Doubles which are equivalent to their 32-bit float representation can be represented as the 4-octet float and then cast to double.
+// ===> double mill
+// Doubles which are equivalent to a 32-bit int of milliunits can be represented as the 4-octet int,
+// which is read as BC_DOUBLE_MILL by java Hessian2Input, rather than the float described by the spec.
+// 	value = 0.001 * (b3 << 24 + b2 << 16 + b1 << 8 + b0)
+//
+// ===> others
+// The other doubles are represented by the 8-octet form, including NaN, the infinities and the negative zero,
+// so that they are decoded to the same value.
 
 package hessian
 
@@ -60,9 +66,9 @@ const (
 	_doubleTwoByteTag   = byte(0x5e)
 	_doubleFourByteTag  = byte(0x5f)
 	_doubleOneByteMin   = -0x80   // -128
-	_doubleOneByteMax   = -0x7f   // 127
+	_doubleOneByteMax   = 0x7f    // 127
 	_doubleTwoByteMin   = -0x8000 // -32768.0
-	_doubleTwoByteMax   = -0x7fff // 32767.0
+	_doubleTwoByteMax   = 0x7fff  // 32767.0
 )
 
 func doubleTag(tag byte) bool {
@@ -76,8 +82,11 @@ func doubleTag(tag byte) bool {
 
 // see: http://hessian.caucho.com/doc/hessian-serialization.html##double
 func encodeDouble(value float64) ([]byte, error) {
-	v := float64(int64(value))
-	if v == value {
+	if math.IsNaN(value) || math.IsInf(value, 0) || (value == 0 && math.Signbit(value)) {
+		return encodeDoubleLong(value), nil
+	}
+
+	if value == math.Trunc(value) && value >= _doubleTwoByteMin && value <= _doubleTwoByteMax {
 		iv := int64(value)
 		if iv == 0 {
 			return []byte{_doubleZeroTag}, nil
@@ -90,23 +99,24 @@ func encodeDouble(value float64) ([]byte, error) {
 			return []byte{_doubleOneByteTag, byte(int8(iv))}, nil
 		}
 
-		if iv >= _doubleTwoByteMin && iv <= _doubleTwoByteMax {
-			return []byte{_doubleTwoByteTag, byte(iv >> 8), byte(iv)}, nil
-		}
-		return nil, newCodecError("encodeDouble", "unsupported double range: %v", iv)
+		return []byte{_doubleTwoByteTag, byte(iv >> 8), byte(iv)}, nil
 	}
 
-	f32 := float32(value)
-	f3264 := float64(f32)
-	if f3264 == value {
-		bits := math.Float32bits(f32)
+	// the same check as java Hessian2Output, so that the value is decoded exactly
+	if mills := value * 1000; mills == math.Trunc(mills) && mills >= math.MinInt32 && mills <= math.MaxInt32 && 0.001*mills == value {
+		im := int32(mills)
 		return []byte{_doubleFourByteTag,
-			byte(bits >> 24),
-			byte(bits >> 16),
-			byte(bits >> 8),
-			byte(bits)}, nil
+			byte(im >> 24),
+			byte(im >> 16),
+			byte(im >> 8),
+			byte(im)}, nil
 	}
 
+	return encodeDoubleLong(value), nil
+}
+
+// 8 octet double
+func encodeDoubleLong(value float64) []byte {
 	bits := uint64(math.Float64bits(value))
 	return []byte{_doubleLongStartTag,
 		byte(bits >> 56),
@@ -116,7 +126,7 @@ func encodeDouble(value float64) ([]byte, error) {
 		byte(bits >> 24),
 		byte(bits >> 16),
 		byte(bits >> 8),
-		byte(bits)}, nil
+		byte(bits)}
 }
 
 func decodeDouble(reader ByteRuneReader) (float64, error) {
@@ -153,9 +163,8 @@ func decodeDoubleValue(reader ByteRuneReader, flag int32) (float64, error) {
 		if err != nil {
 			return 0, err
 		}
-		bits := binary.BigEndian.Uint32(buf)
-		f32 := math.Float32frombits(bits)
-		return float64(f32), nil
+		mills := int32(binary.BigEndian.Uint32(buf))
+		return 0.001 * float64(mills), nil
 	case _doubleLongStartTag:
 		buf, err := readBytes(reader, 8)
 		if err != nil {
@@ -44,9 +44,9 @@ func TestDouble(t *testing.T) {
 	doubleTest(t, _doubleTwoByteMin+1, 3)
 	doubleTest(t, _doubleTwoByteMax, 3)
 
-	doubleTest(t, math.MaxFloat32, 5)
-	doubleTest(t, math.MaxFloat32-1, 5)
-	doubleTest(t, math.MaxFloat32+1, 5)
+	doubleTest(t, math.MaxFloat32, 9)
+	doubleTest(t, math.MaxFloat32-1, 9)
+	doubleTest(t, math.MaxFloat32+1, 9)
 
 	doubleTest(t, math.MaxFloat64, 9)
 	doubleTest(t, math.MaxFloat64-1, 9)
@@ -66,3 +66,68 @@ func doubleTest(t *testing.T, f64 float64, length int) {
 
 	assert.Equal(t, f64, d64)
 }
+
+func TestDoubleFullRange(t *testing.T) {
+	doubleTest(t, 128, 3)
+	doubleTest(t, -129, 3)
+	doubleTest(t, 32768, 5)
+	doubleTest(t, -32769, 5)
+	doubleTest(t, 2147483.647, 5)
+	doubleTest(t, -2147483.648, 5)
+	doubleTest(t, 2147483.648, 9)
+	doubleTest(t, 3000000.0, 9)
+	doubleTest(t, 1e10, 9)
+	doubleTest(t, 1e17+1, 9)
+	doubleTest(t, -1e300, 9)
+	doubleTest(t, math.MaxInt64, 9)
+	doubleTest(t, math.SmallestNonzeroFloat64, 9)
+	doubleTest(t, 12.25, 5)
+	doubleTest(t, 0.1, 5)
+	doubleTest(t, 0.0001, 9)
+	doubleTest(t, 1.0/3, 9)
+}
+
+// the doubles of milliunits written by java Hessian2Output
+func TestDoubleMill(t *testing.T) {
+	for _, c := range []struct {
+		encoded []byte
+		value   float64
+	}{
+		{[]byte{0x5f, 0x00, 0x00, 0x30, 0x39}, 12.345},
+		{[]byte{0x5f, 0x00, 0x00, 0x2f, 0xda}, 12.25},
+		{[]byte{0x5f, 0xff, 0xff, 0xff, 0xff}, -0.001},
+	} {
+		d64, err := decodeDouble(bufio.NewReader(bytes.NewReader(c.encoded)))
+		assert.Nil(t, err)
+		assert.Equal(t, c.value, d64)
+
+		bt, err := encodeDouble(c.value)
+		assert.Nil(t, err)
+		assert.Equal(t, c.encoded, bt)
+	}
+}
+
+func TestDoubleSpecialValues(t *testing.T) {
+	for _, f64 := range []float64{math.NaN(), math.Inf(1), math.Inf(-1), math.Copysign(0, -1)} {
+		bt, err := encodeDouble(f64)
+		assert.Nil(t, err)
+		assert.Equal(t, 9, len(bt))
+		assert.Equal(t, _doubleLongStartTag, bt[0])
+
+		d64, err := decodeDouble(bufio.NewReader(bytes.NewReader(bt)))
+		assert.Nil(t, err)
+		assert.Equal(t, math.Float64bits(f64), math.Float64bits(d64))
+	}
+
+	type priceT struct {
+		Amount float64
+		Rate   float64
+	}
+	typMap, nameMap := ExtractTypeNameMap(priceT{})
+	p := &priceT{Amount: 3000000.0, Rate: math.Inf(1)}
+	bs, err := ToBytes(p, nameMap)
+	assert.Nil(t, err)
+	decoded, err := ToObject(bs, typMap)
+	assert.Nil(t, err)
+	assert.Equal(t, p, decoded)
+}
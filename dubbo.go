@@ -0,0 +1,450 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+// see: http://dubbo.apache.org/en-us/blog/dubbo-protocol.html
+//
+// Dubbo Frame
+//
+// 0       2       3       4                              12              16
+// +-------+-------+-------+------------------------------+---------------+
+// | magic | flag  |status |          request id          |  body length  |
+// +-------+-------+-------+------------------------------+---------------+
+// |                          body ...                                    |
+// +----------------------------------------------------------------------+
+//
+// magic:  0xdabb
+// flag:   0x80 request, 0x40 two way, 0x20 event, the low 5 bits are the serialization id (2 for hessian2)
+// status: the response status, 20 is OK
+//
+// The body is a sequence of hessian2 values sharing the same refs and class-defs.
+//
+// request body:
+//   dubbo version, service path, service version, method name, parameter types desc,
+//   arguments..., attachments map
+//
+// response body:
+//   int response type, then by type:
+//     0 exception
+//     1 value
+//     2 (null value)
+//     3 exception, attachments map
+//     4 value, attachments map
+//     5 attachments map (null value)
+//
+// An event (e.g. heartbeat) body is a null.
+// A response with a status other than OK contains the error message string.
+
+package hessian
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	_dubboHeaderLength = 16
+	_dubboMagicHigh    = byte(0xda)
+	_dubboMagicLow     = byte(0xbb)
+
+	_dubboFlagRequest    = byte(0x80)
+	_dubboFlagTwoWay     = byte(0x40)
+	_dubboFlagEvent      = byte(0x20)
+	_dubboSerialIDMask   = byte(0x1f)
+	_dubboHessian2Serial = byte(2)
+
+	_dubboResponseWithException                = 0
+	_dubboResponseValue                        = 1
+	_dubboResponseNullValue                    = 2
+	_dubboResponseWithExceptionWithAttachments = 3
+	_dubboResponseValueWithAttachments         = 4
+	_dubboResponseNullValueWithAttachments     = 5
+
+	_dubboPathKey      = "path"
+	_dubboInterfaceKey = "interface"
+	_dubboVersionKey   = "version"
+
+	// DubboVersion the default dubbo protocol version of request
+	DubboVersion = "2.0.2"
+
+	// DubboDefaultPayload the default max length of the response body, the same as the default payload of dubbo
+	DubboDefaultPayload = 8 * 1024 * 1024
+)
+
+// dubbo response status
+const (
+	DubboOK                           = byte(20)
+	DubboClientTimeout                = byte(30)
+	DubboServerTimeout                = byte(31)
+	DubboBadRequest                   = byte(40)
+	DubboBadResponse                  = byte(50)
+	DubboServiceNotFound              = byte(60)
+	DubboServiceError                 = byte(70)
+	DubboServerError                  = byte(80)
+	DubboClientError                  = byte(90)
+	DubboServerThreadpoolExhaustedErr = byte(100)
+)
+
+// DubboRequest a dubbo request
+type DubboRequest struct {
+	ID     int64
+	TwoWay bool
+
+	// Event marks a heartbeat request, which has no invocation
+	Event bool
+
+	// DubboVersion default DubboVersion
+	DubboVersion string
+	Path         string
+	Version      string
+	Method       string
+
+	// ParamTypes the jvm descriptors of the method parameter types, e.g. "Ljava/lang/String;I",
+	// it's generated from the arguments if empty
+	ParamTypes  string
+	Args        []interface{}
+	Attachments map[string]interface{}
+}
+
+// DubboResponse a dubbo response
+type DubboResponse struct {
+	ID     int64
+	Status byte
+
+	// Event marks a heartbeat response
+	Event bool
+
+	Value       interface{}
+	Exception   interface{}
+	Attachments map[string]interface{}
+
+	// ErrorMessage the error message of the response whose status is not OK
+	ErrorMessage string
+}
+
+// DubboCodec encode dubbo requests and decode dubbo responses with hessian2 serialization,
+// the type map and name map are the same as those of NewSerializer.
+// A codec can be used concurrently.
+type DubboCodec struct {
+	typMap  map[string]reflect.Type
+	nameMap map[string]string
+	payload int
+}
+
+// DubboCodecOption option to create dubbo codec
+type DubboCodecOption func(c *DubboCodec)
+
+// WithDubboPayload set the max length of the response body, default DubboDefaultPayload
+func WithDubboPayload(payload int) DubboCodecOption {
+	return func(c *DubboCodec) {
+		c.payload = payload
+	}
+}
+
+// NewDubboCodec create a dubbo codec
+func NewDubboCodec(typMap map[string]reflect.Type, nameMap map[string]string, opts ...DubboCodecOption) *DubboCodec {
+	if nameMap == nil {
+		nameMap = make(map[string]string, 11)
+	}
+	c := &DubboCodec{
+		typMap:  typMap,
+		nameMap: nameMap,
+		payload: DubboDefaultPayload,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// EncodeRequest encode request to a dubbo frame
+func (c *DubboCodec) EncodeRequest(req *DubboRequest) ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, _dubboHeaderLength))
+	if err := c.encodeRequestBody(buf, req); err != nil {
+		return nil, err
+	}
+	frame := buf.Bytes()
+
+	flag := _dubboFlagRequest | _dubboHessian2Serial
+	if req.TwoWay {
+		flag |= _dubboFlagTwoWay
+	}
+	if req.Event {
+		flag |= _dubboFlagEvent
+	}
+	frame[0] = _dubboMagicHigh
+	frame[1] = _dubboMagicLow
+	frame[2] = flag
+	binary.BigEndian.PutUint64(frame[4:], uint64(req.ID))
+	binary.BigEndian.PutUint32(frame[12:], uint32(len(frame)-_dubboHeaderLength))
+	return frame, nil
+}
+
+// WriteRequest write the request frame to writer
+func (c *DubboCodec) WriteRequest(w io.Writer, req *DubboRequest) error {
+	frame, err := c.EncodeRequest(req)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(frame)
+	return err
+}
+
+func (c *DubboCodec) encodeRequestBody(w io.Writer, req *DubboRequest) error {
+	e := NewEncoder(w, c.nameMap)
+	if req.Event {
+		_, err := e.WriteData(nil)
+		return err
+	}
+
+	dubboVersion := req.DubboVersion
+	if dubboVersion == "" {
+		dubboVersion = DubboVersion
+	}
+	paramTypes := req.ParamTypes
+	if paramTypes == "" {
+		paramTypes = c.paramTypes(req.Args)
+	}
+
+	attachments := make(map[string]interface{}, len(req.Attachments)+3)
+	attachments[_dubboPathKey] = req.Path
+	attachments[_dubboInterfaceKey] = req.Path
+	if req.Version != "" {
+		attachments[_dubboVersionKey] = req.Version
+	}
+	for k, v := range req.Attachments {
+		attachments[k] = v
+	}
+
+	for _, s := range []string{dubboVersion, req.Path, req.Version, req.Method, paramTypes} {
+		if _, err := e.WriteData(s); err != nil {
+			return newCodecError("EncodeRequest", err)
+		}
+	}
+	for i, arg := range req.Args {
+		if _, err := e.WriteData(arg); err != nil {
+			return newCodecError("EncodeRequest", "argument %d", i, err)
+		}
+	}
+	if _, err := e.WriteData(attachments); err != nil {
+		return newCodecError("EncodeRequest", "attachments", err)
+	}
+	return nil
+}
+
+// ReadResponse read a response frame from reader
+func (c *DubboCodec) ReadResponse(r io.Reader) (*DubboResponse, error) {
+	header := make([]byte, _dubboHeaderLength)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if header[0] != _dubboMagicHigh || header[1] != _dubboMagicLow {
+		return nil, newCodecError("ReadResponse", "error magic: 0x%x", header[:2])
+	}
+
+	flag := header[2]
+	if flag&_dubboFlagRequest != 0 {
+		return nil, newCodecError("ReadResponse", "expect response but get request")
+	}
+	if serial := flag & _dubboSerialIDMask; serial != _dubboHessian2Serial {
+		return nil, newCodecError("ReadResponse", "unsupported serialization id: %d", serial)
+	}
+
+	length := int32(binary.BigEndian.Uint32(header[12:]))
+	// reject the length before allocating the body
+	if length < 0 || int(length) > c.payload {
+		return nil, newCodecError("ReadResponse", "error body length: %d, max %d", length, c.payload)
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, newCodecError("ReadResponse", "read body", err)
+	}
+
+	res := &DubboResponse{
+		ID:     int64(binary.BigEndian.Uint64(header[4:])),
+		Status: header[3],
+		Event:  flag&_dubboFlagEvent != 0,
+	}
+	if err := c.decodeResponseBody(body, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// DecodeResponse decode a response frame
+func (c *DubboCodec) DecodeResponse(frame []byte) (*DubboResponse, error) {
+	return c.ReadResponse(bytes.NewReader(frame))
+}
+
+func (c *DubboCodec) decodeResponseBody(body []byte, res *DubboResponse) error {
+	d := NewDecoder(bufio.NewReader(bytes.NewReader(body)), c.typMap)
+
+	if res.Status != DubboOK {
+		msg, err := d.ReadObject()
+		if err != nil {
+			return newCodecError("DecodeResponse", "read error message", err)
+		}
+		res.ErrorMessage, _ = msg.(string)
+		return nil
+	}
+
+	if res.Event {
+		value, err := d.ReadObject()
+		if err != nil {
+			return newCodecError("DecodeResponse", "read event", err)
+		}
+		res.Value = value
+		return nil
+	}
+
+	typ, err := d.readInt(_tagRead)
+	if err != nil {
+		return newCodecError("DecodeResponse", "read response type", err)
+	}
+
+	var withAttachments bool
+	switch typ {
+	case _dubboResponseNullValue:
+	case _dubboResponseNullValueWithAttachments:
+		withAttachments = true
+	case _dubboResponseValue, _dubboResponseValueWithAttachments:
+		withAttachments = typ == _dubboResponseValueWithAttachments
+		if res.Value, err = d.ReadObject(); err != nil {
+			return newCodecError("DecodeResponse", "read value", err)
+		}
+	case _dubboResponseWithException, _dubboResponseWithExceptionWithAttachments:
+		withAttachments = typ == _dubboResponseWithExceptionWithAttachments
+		if res.Exception, err = d.ReadObject(); err != nil {
+			return newCodecError("DecodeResponse", "read exception", err)
+		}
+	default:
+		return newCodecError("DecodeResponse", "unknown response type: %d", typ)
+	}
+
+	if withAttachments {
+		m, err := d.ReadObject()
+		if err != nil {
+			return newCodecError("DecodeResponse", "read attachments", err)
+		}
+		res.Attachments = toAttachments(m)
+	}
+	return nil
+}
+
+func toAttachments(m interface{}) map[string]interface{} {
+	mv := reflect.ValueOf(m)
+	if mv.Kind() != reflect.Map {
+		return nil
+	}
+	attachments := make(map[string]interface{}, mv.Len())
+	for _, k := range mv.MapKeys() {
+		if key, ok := k.Interface().(string); ok {
+			attachments[key] = mv.MapIndex(k).Interface()
+		}
+	}
+	return attachments
+}
+
+var _dubboBoxedTypeDesc = map[reflect.Kind]string{
+	reflect.Bool:    "Ljava/lang/Boolean;",
+	reflect.Int8:    "Ljava/lang/Byte;",
+	reflect.Int16:   "Ljava/lang/Short;",
+	reflect.Int32:   "Ljava/lang/Integer;",
+	reflect.Int:     "Ljava/lang/Integer;",
+	reflect.Uint8:   "Ljava/lang/Integer;",
+	reflect.Uint16:  "Ljava/lang/Integer;",
+	reflect.Int64:   "Ljava/lang/Long;",
+	reflect.Uint:    "Ljava/lang/Long;",
+	reflect.Uint32:  "Ljava/lang/Long;",
+	reflect.Uint64:  "Ljava/lang/Long;",
+	reflect.Float32: "Ljava/lang/Float;",
+	reflect.Float64: "Ljava/lang/Double;",
+	reflect.String:  "Ljava/lang/String;",
+}
+
+var _dubboPrimitiveTypeDesc = map[reflect.Kind]string{
+	reflect.Bool:    "Z",
+	reflect.Int8:    "B",
+	reflect.Int16:   "S",
+	reflect.Int32:   "I",
+	reflect.Int:     "I",
+	reflect.Uint8:   "I",
+	reflect.Uint16:  "I",
+	reflect.Int64:   "J",
+	reflect.Uint:    "J",
+	reflect.Uint32:  "J",
+	reflect.Uint64:  "J",
+	reflect.Float32: "F",
+	reflect.Float64: "D",
+	reflect.String:  "Ljava/lang/String;",
+}
+
+// the jvm descriptors of the argument types,
+// a go primitive is described as java primitive, and a pointer to go primitive as java boxed type.
+func (c *DubboCodec) paramTypes(args []interface{}) string {
+	buf := bytes.NewBuffer(nil)
+	for _, arg := range args {
+		buf.WriteString(c.typeDesc(arg))
+	}
+	return buf.String()
+}
+
+func (c *DubboCodec) typeDesc(arg interface{}) string {
+	if arg == nil {
+		return "Ljava/lang/Object;"
+	}
+	if _, ok := arg.(time.Time); ok {
+		return "Ljava/util/Date;"
+	}
+	if _, ok := arg.([]byte); ok {
+		return "[B"
+	}
+
+	typ := reflect.TypeOf(arg)
+	descMap := _dubboPrimitiveTypeDesc
+	if typ.Kind() == reflect.Ptr {
+		descMap = _dubboBoxedTypeDesc
+		typ = UnpackPtrType(typ)
+	}
+	if desc, ok := descMap[typ.Kind()]; ok {
+		return desc
+	}
+
+	switch typ.Kind() {
+	case reflect.Slice, reflect.Array:
+		return "Ljava/util/List;"
+	case reflect.Map:
+		return "Ljava/util/Map;"
+	case reflect.Struct:
+		if typ == _dateType {
+			return "Ljava/util/Date;"
+		}
+		name, ok := c.nameMap[typ.Name()]
+		if !ok {
+			if n, isNamable := reflect.New(typ).Elem().Interface().(CodecNamable); isNamable {
+				name = n.HessianCodecName()
+			} else {
+				name = typ.Name()
+			}
+		}
+		return "L" + strings.Replace(name, ".", "/", -1) + ";"
+	default:
+		return "Ljava/lang/Object;"
+	}
+}
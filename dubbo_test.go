@@ -0,0 +1,207 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package hessian
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type dubboExceptionT struct {
+	DetailMessage string
+}
+
+func (dubboExceptionT) HessianCodecName() string {
+	return "java.lang.RuntimeException"
+}
+
+// build a response frame, the body is composed of the values
+func buildDubboResponse(id int64, status byte, flag byte, values ...interface{}) []byte {
+	body := bytes.NewBuffer(nil)
+	e := NewEncoder(body, NameMapFrom(dubboExceptionT{}))
+	for _, v := range values {
+		e.WriteData(v)
+	}
+
+	header := make([]byte, _dubboHeaderLength)
+	header[0] = _dubboMagicHigh
+	header[1] = _dubboMagicLow
+	header[2] = flag | _dubboHessian2Serial
+	header[3] = status
+	binary.BigEndian.PutUint64(header[4:], uint64(id))
+	binary.BigEndian.PutUint32(header[12:], uint32(body.Len()))
+	return append(header, body.Bytes()...)
+}
+
+func TestDubboEncodeRequest(t *testing.T) {
+	codec := NewDubboCodec(ExtractTypeNameMap(P{}))
+	p := P{X: 1, Y: 2, Name: "p"}
+	frame, err := codec.EncodeRequest(&DubboRequest{
+		ID:          123,
+		TwoWay:      true,
+		Path:        "com.test.PointService",
+		Version:     "1.0.0",
+		Method:      "move",
+		Args:        []interface{}{p, int32(3), int64(4)},
+		Attachments: map[string]interface{}{"timeout": "3000"},
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, []byte{0xda, 0xbb, 0xc2, 0x00}, frame[:4])
+	assert.Equal(t, int64(123), int64(binary.BigEndian.Uint64(frame[4:12])))
+	assert.Equal(t, len(frame)-_dubboHeaderLength, int(binary.BigEndian.Uint32(frame[12:16])))
+
+	d := NewDecoder(bufio.NewReader(bytes.NewReader(frame[_dubboHeaderLength:])), TypeMapFrom(P{}))
+	for _, expect := range []interface{}{DubboVersion, "com.test.PointService", "1.0.0", "move", "LP;IJ", &p, int32(3), int64(4)} {
+		v, err := d.ReadObject()
+		assert.Nil(t, err)
+		assert.Equal(t, expect, v)
+	}
+
+	attachments, err := d.ReadObject()
+	assert.Nil(t, err)
+	assert.Equal(t, map[interface{}]interface{}{
+		"path":      "com.test.PointService",
+		"interface": "com.test.PointService",
+		"version":   "1.0.0",
+		"timeout":   "3000",
+	}, attachments)
+}
+
+func TestDubboParamTypes(t *testing.T) {
+	codec := NewDubboCodec(nil, nil)
+	i := int32(1)
+	s := "s"
+	assert.Equal(t, "Ljava/lang/String;ZIJD[BLjava/util/List;Ljava/util/Map;Ljava/lang/Integer;Ljava/lang/String;Lhessian/TraceVo;Ljava/lang/Object;",
+		codec.paramTypes([]interface{}{"s", true, int32(1), int64(1), 1.0, []byte{1}, []string{"s"}, map[string]string{}, &i, &s, traceVoT{}, nil}))
+}
+
+func TestDubboHeartbeat(t *testing.T) {
+	codec := NewDubboCodec(nil, nil)
+	frame, err := codec.EncodeRequest(&DubboRequest{ID: 1, TwoWay: true, Event: true})
+	assert.Nil(t, err)
+	assert.Equal(t, byte(0xe2), frame[2])
+	assert.Equal(t, []byte{_nilTag}, frame[_dubboHeaderLength:])
+
+	res, err := codec.DecodeResponse(buildDubboResponse(1, DubboOK, _dubboFlagEvent, nil))
+	assert.Nil(t, err)
+	assert.True(t, res.Event)
+	assert.Equal(t, int64(1), res.ID)
+	assert.Nil(t, res.Value)
+}
+
+func TestDubboDecodeResponse(t *testing.T) {
+	typMap, nameMap := ExtractTypeNameMap(dubboExceptionT{})
+	codec := NewDubboCodec(typMap, nameMap)
+
+	res, err := codec.DecodeResponse(buildDubboResponse(2, DubboOK, 0, int32(_dubboResponseValue), "hello"))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), res.ID)
+	assert.Equal(t, DubboOK, res.Status)
+	assert.Equal(t, "hello", res.Value)
+	assert.Nil(t, res.Exception)
+	assert.Nil(t, res.Attachments)
+
+	res, err = codec.DecodeResponse(buildDubboResponse(3, DubboOK, 0, int32(_dubboResponseNullValue)))
+	assert.Nil(t, err)
+	assert.Nil(t, res.Value)
+
+	res, err = codec.DecodeResponse(buildDubboResponse(4, DubboOK, 0,
+		int32(_dubboResponseValueWithAttachments), int32(5), map[string]string{"k": "v"}))
+	assert.Nil(t, err)
+	assert.Equal(t, int32(5), res.Value)
+	assert.Equal(t, map[string]interface{}{"k": "v"}, res.Attachments)
+
+	res, err = codec.DecodeResponse(buildDubboResponse(5, DubboOK, 0,
+		int32(_dubboResponseNullValueWithAttachments), map[string]string{"k": "v"}))
+	assert.Nil(t, err)
+	assert.Nil(t, res.Value)
+	assert.Equal(t, map[string]interface{}{"k": "v"}, res.Attachments)
+
+	res, err = codec.DecodeResponse(buildDubboResponse(6, DubboOK, 0,
+		int32(_dubboResponseWithException), dubboExceptionT{DetailMessage: "failed"}))
+	assert.Nil(t, err)
+	assert.Nil(t, res.Value)
+	assert.Equal(t, &dubboExceptionT{DetailMessage: "failed"}, res.Exception)
+
+	res, err = codec.DecodeResponse(buildDubboResponse(7, DubboServiceNotFound, 0, "service not found"))
+	assert.Nil(t, err)
+	assert.Equal(t, DubboServiceNotFound, res.Status)
+	assert.Equal(t, "service not found", res.ErrorMessage)
+}
+
+func TestDubboReadResponseStream(t *testing.T) {
+	codec := NewDubboCodec(nil, nil)
+	stream := bytes.NewBuffer(nil)
+	stream.Write(buildDubboResponse(1, DubboOK, 0, int32(_dubboResponseValue), "a"))
+	stream.Write(buildDubboResponse(2, DubboOK, 0, int32(_dubboResponseValue), "b"))
+
+	for i, expect := range []string{"a", "b"} {
+		res, err := codec.ReadResponse(stream)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(i+1), res.ID)
+		assert.Equal(t, expect, res.Value)
+	}
+
+	_, err := codec.DecodeResponse([]byte{0xca, 0xfe, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0})
+	assert.NotNil(t, err)
+}
+
+func TestDubboPayload(t *testing.T) {
+	frame := buildDubboResponse(1, DubboOK, 0, int32(_dubboResponseValue), "abc")
+
+	res, err := NewDubboCodec(nil, nil, WithDubboPayload(len(frame)-_dubboHeaderLength)).DecodeResponse(frame)
+	assert.Nil(t, err)
+	assert.Equal(t, "abc", res.Value)
+
+	_, err = NewDubboCodec(nil, nil, WithDubboPayload(len(frame)-_dubboHeaderLength-1)).DecodeResponse(frame)
+	assert.NotNil(t, err)
+
+	// the length larger than the default payload, or negative, is rejected without reading the body
+	for _, length := range []uint32{DubboDefaultPayload + 1, 0xffffffff} {
+		binary.BigEndian.PutUint32(frame[12:], length)
+		_, err = NewDubboCodec(nil, nil).DecodeResponse(frame)
+		if assert.NotNil(t, err) {
+			assert.Contains(t, err.Error(), "error body length")
+		}
+	}
+}
+
+func TestDubboConcurrent(t *testing.T) {
+	// the classes not in the name map are written by the go type names
+	nameMap := map[string]string{}
+	codec := NewDubboCodec(nil, nameMap)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			frame, err := codec.EncodeRequest(&DubboRequest{ID: int64(i), Path: "com.test.PointService", Method: "sum", Args: []interface{}{&P{X: i}}})
+			assert.Nil(t, err)
+			assert.Equal(t, byte(i), frame[11])
+		}(i)
+	}
+	wg.Wait()
+
+	// the name map shared by the requests is not changed
+	assert.Empty(t, nameMap)
+}
@@ -0,0 +1,135 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+// Dynamic object.
+//
+// An object whose class is not registered in the type map can be decoded as *Object with the option WithDynamicObject,
+// which holds the class name, the field names in the order of the class definition and the field values.
+// An Object is written with the same class definition, so that it can be forwarded without loss.
+
+package hessian
+
+import (
+	"reflect"
+)
+
+var _objectType = reflect.TypeOf(Object{})
+
+// Object a dynamic object of java class
+type Object struct {
+	ClassName string
+	Fields    []string
+	Values    []interface{}
+}
+
+// NewObject create a dynamic object of the class without fields
+func NewObject(className string) *Object {
+	return &Object{ClassName: className}
+}
+
+// Len return the count of fields
+func (o *Object) Len() int {
+	return len(o.Fields)
+}
+
+// Get return the value of the field, false is returned if the field doesn't exist
+func (o *Object) Get(field string) (interface{}, bool) {
+	for i, f := range o.Fields {
+		if f == field {
+			return o.Values[i], true
+		}
+	}
+	return nil, false
+}
+
+// Set set the value of the field, the field is appended if it doesn't exist
+func (o *Object) Set(field string, value interface{}) {
+	for i, f := range o.Fields {
+		if f == field {
+			o.Values[i] = value
+			return
+		}
+	}
+	o.Fields = append(o.Fields, field)
+	o.Values = append(o.Values, value)
+}
+
+// Map return the fields and values as a map
+func (o *Object) Map() map[string]interface{} {
+	m := make(map[string]interface{}, len(o.Fields))
+	for i, f := range o.Fields {
+		m[f] = o.Values[i]
+	}
+	return m
+}
+
+func (e *Encoder) writeDynamicObject(source interface{}, o *Object) (int, error) {
+	if len(o.Fields) != len(o.Values) {
+		return 0, newCodecError("writeDynamicObject", "%d fields but %d values of %s", len(o.Fields), len(o.Values), o.ClassName)
+	}
+	if n, ok := e.checkEncodeRefMap(reflect.ValueOf(source)); ok {
+		return e.writeRef(n)
+	}
+
+	i := 0
+	return e.writeClassObject(o.ClassName, o.Fields, func(string) error {
+		_, err := e.WriteData(o.Values[i])
+		i++
+		return err
+	})
+}
+
+// read the dynamic object after the object tag
+func (d *Decoder) readDynamicObject(cls ClassDef) (interface{}, error) {
+	o := &Object{
+		ClassName: cls.FullClassName,
+		Fields:    append([]string{}, cls.FieldName...),
+		Values:    make([]interface{}, len(cls.FieldName)),
+	}
+	d.addDecoderRef(reflect.ValueOf(o))
+
+	for i, fldName := range cls.FieldName {
+		value, err := d.ReadObject()
+		if err != nil {
+			return nil, newCodecError("readDynamicObject", "failed to decode field '%s'", fldName, err)
+		}
+		o.Values[i] = value
+	}
+	return o, nil
+}
+
+// read the dynamic object after the type of hessian 1.0 map
+func (d *Decoder) readDynamicObject1(className string, tag byte) (interface{}, error) {
+	o := NewObject(className)
+	d.addDecoderRef(reflect.ValueOf(o))
+
+	for tag != _end1Flag {
+		fldName, err := d.readString(int32(tag))
+		if err != nil {
+			return nil, newCodecError("readDynamicObject1", "read field name", err)
+		}
+		value, err := EnsureInterface(d.readData1(_tagRead))
+		if err != nil {
+			return nil, newCodecError("readDynamicObject1", "failed to decode field '%s'", fldName, err)
+		}
+		o.Fields = append(o.Fields, fldName)
+		o.Values = append(o.Values, value)
+
+		if tag, err = d.readTag(); err != nil {
+			return nil, newCodecError("readDynamicObject1", err)
+		}
+	}
+	return o, nil
+}
@@ -0,0 +1,140 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package hessian
+
+import (
+	"bufio"
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type dynamicCarT struct {
+	Color  string
+	Model  string
+	Wheels []int32
+	Owner  *dynamicOwnerT
+}
+
+type dynamicOwnerT struct {
+	Name string
+}
+
+type dynamicGarageT struct {
+	Cars []*dynamicCarT
+	Best *dynamicCarT
+}
+
+func TestDynamicObject(t *testing.T) {
+	owner := &dynamicOwnerT{Name: "tom"}
+	corvette := &dynamicCarT{Color: "red", Model: "corvette", Wheels: []int32{1, 2}, Owner: owner}
+	garage := &dynamicGarageT{
+		Cars: []*dynamicCarT{corvette, {Color: "green", Model: "civic", Owner: owner}},
+		Best: corvette,
+	}
+	nameMap := map[string]string{
+		"dynamicCarT":    "example.Car",
+		"dynamicOwnerT":  "example.Owner",
+		"dynamicGarageT": "example.Garage",
+	}
+
+	for _, version := range []int{ProtocolVersion2, ProtocolVersion1} {
+		bs, err := ToBytes(garage, nameMap, WithProtocolVersion(version))
+		assert.Nil(t, err)
+
+		_, err = ToObject(bs, nil, WithProtocolVersion(version))
+		if version == ProtocolVersion2 {
+			assert.NotNil(t, err)
+		}
+
+		decoded, err := ToObject(bs, nil, WithProtocolVersion(version), WithDynamicObject())
+		assert.Nil(t, err)
+		o, ok := decoded.(*Object)
+		if !assert.True(t, ok, "expect *Object but get %v", decoded) {
+			continue
+		}
+		assert.Equal(t, "example.Garage", o.ClassName)
+		assert.Equal(t, []string{"cars", "best"}, o.Fields)
+
+		best, _ := o.Get("best")
+		car, ok := best.(*Object)
+		if assert.True(t, ok) {
+			assert.Equal(t, "example.Car", car.ClassName)
+			assert.Equal(t, map[string]interface{}{
+				"color":  "red",
+				"model":  "corvette",
+				"wheels": []interface{}{int32(1), int32(2)},
+				"owner":  &Object{ClassName: "example.Owner", Fields: []string{"name"}, Values: []interface{}{"tom"}},
+			}, car.Map())
+		}
+		cars, _ := o.Get("cars")
+		assert.True(t, cars.([]interface{})[0] == best)
+
+		// written back with the same class definitions and refs
+		rewritten, err := ToBytes(o, nil, WithProtocolVersion(version))
+		assert.Nil(t, err)
+		assert.Equal(t, bs, rewritten)
+	}
+}
+
+func TestDynamicObjectAccessors(t *testing.T) {
+	o := NewObject("example.Car")
+	o.Set("color", "red")
+	o.Set("model", "civic")
+	o.Set("color", "green")
+	assert.Equal(t, 2, o.Len())
+	assert.Equal(t, []string{"color", "model"}, o.Fields)
+
+	v, ok := o.Get("color")
+	assert.True(t, ok)
+	assert.Equal(t, "green", v)
+	_, ok = o.Get("mileage")
+	assert.False(t, ok)
+
+	bs, err := ToBytes(o, nil)
+	assert.Nil(t, err)
+	decoded, err := ToObject(bs, map[string]reflect.Type{"example.Car": reflect.TypeOf(dynamicCarT{})})
+	assert.Nil(t, err)
+	assert.Equal(t, &dynamicCarT{Color: "green", Model: "civic"}, decoded)
+
+	// the same class with different fields
+	buf := bytes.NewBuffer(nil)
+	e := NewEncoder(buf, nil)
+	assert.Nil(t, e.WriteObject(o))
+	assert.Nil(t, e.WriteObject(&Object{ClassName: "example.Car", Fields: []string{"mileage"}, Values: []interface{}{int32(1)}}))
+	d := NewDecoder(nil, nil, WithDynamicObject())
+	d.Reset(bufio.NewReader(buf))
+	_, err = d.ReadObject()
+	assert.Nil(t, err)
+	decoded, err = d.ReadObject()
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"mileage": int32(1)}, decoded.(*Object).Map())
+
+	_, err = ToBytes(&Object{ClassName: "example.Car", Fields: []string{"color"}}, nil)
+	assert.NotNil(t, err)
+}
+
+func TestDynamicObjectField(t *testing.T) {
+	bs, err := ToBytes(&dynamicCarT{Owner: &dynamicOwnerT{Name: "tom"}}, map[string]string{"dynamicOwnerT": "example.Owner"})
+	assert.Nil(t, err)
+
+	// the struct field is decoded as its type even if the class is not registered
+	decoded, err := ToObject(bs, map[string]reflect.Type{"dynamicCarT": reflect.TypeOf(dynamicCarT{})}, WithDynamicObject())
+	assert.Nil(t, err)
+	assert.Equal(t, &dynamicCarT{Owner: &dynamicOwnerT{Name: "tom"}}, decoded)
+}
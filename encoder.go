@@ -21,7 +21,9 @@ package hessian
 import (
 	"bytes"
 	"io"
+	"math/big"
 	"reflect"
+	"time"
 	"unsafe"
 )
 
@@ -31,15 +33,19 @@ type Encoder struct {
 	clsDefList []ClassDef
 	nameMap    map[string]string
 	refMap     map[unsafe.Pointer]_refElem
+	refCount   int
+	enumRefMap map[_enumRef]int
+	options
 }
 
 //NewEncoder new
-func NewEncoder(w io.Writer, np map[string]string) *Encoder {
+func NewEncoder(w io.Writer, np map[string]string, opts ...Option) *Encoder {
 	if np == nil {
 		np = make(map[string]string, 11)
 	}
 	encoder := &Encoder{
 		nameMap: np,
+		options: newOptions(opts),
 	}
 	if w != nil {
 		encoder.Reset(w)
@@ -52,6 +58,8 @@ func (e *Encoder) Reset(w io.Writer) {
 	e.writer = w
 	e.clsDefList = make([]ClassDef, 0, 11)
 	e.refMap = make(map[unsafe.Pointer]_refElem, 11)
+	e.refCount = 0
+	e.enumRefMap = make(map[_enumRef]int)
 }
 
 //RegisterNameType register name type
@@ -92,6 +100,12 @@ func (e *Encoder) WriteData(data interface{}) (int, error) {
 		e.writeBT(_nilTag)
 		return 1, nil
 	}
+	if m, ok := data.(HessianMarshaler); ok {
+		return e.writeMarshaler(m)
+	}
+	if ok, n, err := e.writeCustom(data); ok {
+		return n, err
+	}
 	source := data
 	v := reflect.ValueOf(data)
 
@@ -106,6 +120,19 @@ func (e *Encoder) WriteData(data interface{}) (int, error) {
 		data = v.Interface()
 	}
 
+	if enum, ok := data.(JavaEnum); ok {
+		return e.writeEnum(v, enum)
+	}
+
+	switch value := data.(type) {
+	case Decimal:
+		return e.writeDecimal(source, value)
+	case big.Int:
+		return e.writeBigInteger(source, &value)
+	case Object:
+		return e.writeDynamicObject(source, &value)
+	}
+
 	switch v.Kind() {
 	case reflect.Bool:
 		value := data.(bool)
@@ -113,36 +140,16 @@ func (e *Encoder) WriteData(data interface{}) (int, error) {
 	case reflect.String:
 		value := data.(string)
 		return e.writeString(value)
-	case reflect.Int8: // as int
-		value := int32(data.(int8))
-		return e.writeInt(value)
-	case reflect.Int16: // as int
-		value := int32(data.(int16))
-		return e.writeInt(value)
-	case reflect.Int32: // as int
-		value := data.(int32)
-		return e.writeInt(value)
-	case reflect.Int: // as int
-		value := int32(data.(int))
-		return e.writeInt(value)
-	case reflect.Uint8: // as int
-		value := int32(data.(uint8))
-		return e.writeInt(value)
-	case reflect.Uint16: // as int
-		value := int32(data.(uint16))
-		return e.writeInt(value)
+	case reflect.Int8, reflect.Int16, reflect.Int32: // as int
+		return e.writeInt(int32(v.Int()))
+	case reflect.Int: // as int, or long if out of the range of int
+		return e.writeInteger(v.Int())
+	case reflect.Uint8, reflect.Uint16: // as int
+		return e.writeInt(int32(v.Uint()))
 	case reflect.Int64: // as long
-		value := data.(int64)
-		return e.writeLong(value)
-	case reflect.Uint: // as long
-		value := int64(data.(uint))
-		return e.writeLong(value)
-	case reflect.Uint32: // as long
-		value := int64(data.(uint32))
-		return e.writeLong(value)
-	case reflect.Uint64: // as long
-		value := int64(data.(uint64))
-		return e.writeLong(value)
+		return e.writeLong(v.Int())
+	case reflect.Uint, reflect.Uint32, reflect.Uint64: // as long, or by the uint overflow policy if out of the range of long
+		return e.writeUnsigned(source, v.Uint())
 	case reflect.Float32:
 		value := data.(float32)
 		return e.writeDouble(float64(value))
@@ -155,23 +162,42 @@ func (e *Encoder) WriteData(data interface{}) (int, error) {
 		return e.writeMap(source)
 	case reflect.Struct:
 		return e.writeObject(source)
+	case reflect.Interface:
+		// the pointer to interface
+		if v.IsNil() {
+			e.writeBT(_nilTag)
+			return 1, nil
+		}
+		return e.WriteData(v.Elem().Interface())
 	}
 	return 0, newCodecError("WriteData", "unsupported object:%v, kind:%v, type:%v", data, v.Kind(), v.Kind())
 }
 
 func (e *Encoder) writeString(value string) (int, error) {
+	if e.isHessian1() {
+		return e.writer.Write(encodeString1(value))
+	}
 	return e.writer.Write(encodeString(value))
 }
 
 func (e *Encoder) writeInt(value int32) (int, error) {
+	if e.isHessian1() {
+		return e.writer.Write(encodeInt1(value))
+	}
 	return e.writer.Write(encodeInt(value))
 }
 
 func (e *Encoder) writeLong(value int64) (int, error) {
+	if e.isHessian1() {
+		return e.writer.Write(encodeLong1(value))
+	}
 	return e.writer.Write(encodeLong(value))
 }
 
 func (e *Encoder) writeDouble(value float64) (int, error) {
+	if e.isHessian1() {
+		return e.writer.Write(encodeDouble1(value))
+	}
 	bytes, err := encodeDouble(value)
 	if err != nil {
 		return 0, err
@@ -184,9 +210,19 @@ func (e *Encoder) writeBoolean(value bool) (int, error) {
 }
 
 func (e *Encoder) writeBinary(value []byte) (int, error) {
+	if e.isHessian1() {
+		return e.writer.Write(encodeBinary1(value))
+	}
 	return e.writer.Write(encodeBinary(value))
 }
 
+func (e *Encoder) writeDate(value time.Time) (int, error) {
+	if e.isHessian1() {
+		return e.writer.Write(encodeDate1(value))
+	}
+	return e.writer.Write(encodeDate(value))
+}
+
 func (e *Encoder) writeBT(bs ...byte) (int, error) {
 	return e.writer.Write(bs)
 }
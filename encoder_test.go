@@ -259,3 +259,68 @@ func TestEncoder_WriteString(t *testing.T) {
 		t.Log("succes for ", str)
 	}
 }
+
+type nullEmptyT struct {
+	Name   string
+	Remark *string
+	Tags   []string
+	Codes  []int32
+	Attrs  map[string]string
+	Extras map[string]int32
+	Data   []byte
+	Raw    []byte
+	Refs   []*string
+	Items  []interface{}
+}
+
+func TestEncodeNullAndEmpty(t *testing.T) {
+	nameMap := map[string]string{"nullEmptyT": "test.NullEmpty"}
+	typMap := map[string]reflect.Type{"test.NullEmpty": reflect.TypeOf(nullEmptyT{})}
+	empty := ""
+	v := &nullEmptyT{
+		Remark: &empty,
+		Tags:   []string{},
+		Attrs:  map[string]string{},
+		Data:   []byte{},
+		Refs:   []*string{nil},
+		Items:  []interface{}{nil, "x"},
+	}
+
+	for _, version := range []int{ProtocolVersion2, ProtocolVersion1} {
+		bs, err := ToBytes(v, nameMap, WithProtocolVersion(version))
+		assert.Nil(t, err)
+
+		o, err := ToObject(bs, nil, WithDynamicObject(), WithProtocolVersion(version))
+		assert.Nil(t, err)
+		assert.Equal(t, []interface{}{"", "", []interface{}{}, nil, map[interface{}]interface{}{}, nil, []byte{}, nil,
+			[]interface{}{nil}, []interface{}{nil, "x"}}, o.(*Object).Values)
+
+		decoded, err := ToObject(bs, typMap, WithProtocolVersion(version))
+		assert.Nil(t, err)
+		assert.Equal(t, v, decoded)
+
+		// the null elements of the typed lists
+		bs, err = ToBytes(v, nameMap, WithProtocolVersion(version), WithJavaListClass("java.util.LinkedList"))
+		assert.Nil(t, err)
+		decoded, err = ToObject(bs, typMap, WithProtocolVersion(version))
+		assert.Nil(t, err)
+		assert.Equal(t, v, decoded)
+	}
+
+	// the empty string is not written as null
+	bs, err := ToBytes("", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x00}, bs)
+
+	// the nil slice, nil map and pointer to nil map are written as null
+	var nilMap map[string]int32
+	for _, data := range []interface{}{[]string(nil), map[string]int32(nil), &nilMap} {
+		bs, err = ToBytes(data, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, []byte{_nilTag}, bs)
+
+		n, err := NewEncoder(bytes.NewBuffer(nil), nil).WriteData(data)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, n)
+	}
+}
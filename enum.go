@@ -0,0 +1,211 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+// Java enum, see the enum example in object.go.
+//
+// A java enum is written as an object with a single field "name", which is the name of the enum constant.
+// As java does, the same enum constant is written only once, the following ones are written as refs.
+//
+// -------------- Java enum examples
+//
+// type Color string
+//
+// func (Color) JavaEnumNames() []string { return []string{"RED", "GREEN", "BLUE"} }
+// func (Color) HessianCodecName() string { return "example.Color" }
+//
+// // or an integer type whose value is the ordinal of the name
+// type Color int32
+//
+// const (
+//   Red Color = iota
+//   Green
+//   Blue
+// )
+//
+// func (Color) JavaEnumNames() []string { return []string{"RED", "GREEN", "BLUE"} }
+// func (Color) HessianCodecName() string { return "example.Color" }
+
+package hessian
+
+import (
+	"reflect"
+)
+
+const _enumNameField = "name"
+
+// JavaEnum a go string or integer type which is mapped to a java enum.
+// A string value is the name of the enum constant, and an integer value is the index of the name.
+// The java class name is the same as struct, which can be defined by CodecNamable.
+type JavaEnum interface {
+	// JavaEnumNames return the names of all the enum constants
+	JavaEnumNames() []string
+}
+
+var _javaEnumType = reflect.TypeOf((*JavaEnum)(nil)).Elem()
+
+// the enum constant to ref
+type _enumRef struct {
+	typ  reflect.Type
+	name string
+}
+
+func isJavaEnumType(typ reflect.Type) bool {
+	switch {
+	case typ.Kind() == reflect.String, IntKind(typ.Kind()), UintKind(typ.Kind()):
+		return typ.Implements(_javaEnumType) || reflect.PtrTo(typ).Implements(_javaEnumType)
+	default:
+		return false
+	}
+}
+
+// the name of enum constant
+func enumName(v reflect.Value, enum JavaEnum) (string, error) {
+	names := enum.JavaEnumNames()
+	switch {
+	case v.Kind() == reflect.String:
+		for _, name := range names {
+			if name == v.String() {
+				return name, nil
+			}
+		}
+		return "", newCodecError("enumName", "unknown enum constant %s of %v", v.String(), v.Type())
+	case IntKind(v.Kind()):
+		if i := v.Int(); i >= 0 && i < int64(len(names)) {
+			return names[i], nil
+		}
+		return "", newCodecError("enumName", "unknown enum constant %d of %v", v.Int(), v.Type())
+	case UintKind(v.Kind()):
+		if i := v.Uint(); i < uint64(len(names)) {
+			return names[i], nil
+		}
+		return "", newCodecError("enumName", "unknown enum constant %d of %v", v.Uint(), v.Type())
+	default:
+		return "", newCodecError("enumName", "unsupported enum type: %v", v.Type())
+	}
+}
+
+// the enum constant of the name
+func enumValue(typ reflect.Type, name string) (reflect.Value, error) {
+	vv := reflect.New(typ)
+	names := vv.Interface().(JavaEnum).JavaEnumNames()
+	v := vv.Elem()
+	for i, n := range names {
+		if n != name {
+			continue
+		}
+		switch {
+		case typ.Kind() == reflect.String:
+			v.SetString(name)
+		case IntKind(typ.Kind()):
+			v.SetInt(int64(i))
+		default:
+			v.SetUint(uint64(i))
+		}
+		return v, nil
+	}
+	return _zeroValue, newCodecError("enumValue", "unknown enum constant %s of %v", name, typ)
+}
+
+func (e *Encoder) writeEnum(v reflect.Value, enum JavaEnum) (int, error) {
+	name, err := enumName(v, enum)
+	if err != nil {
+		return 0, err
+	}
+
+	typ := v.Type()
+	ref := _enumRef{typ, name}
+	if n, ok := e.enumRefMap[ref]; ok {
+		return e.writeRef(n)
+	}
+	e.enumRefMap[ref] = e.addRefPlaceholder()
+
+	clsName, ok := e.nameMap[typ.Name()]
+	if !ok {
+		clsName = typ.Name()
+		if n, isNamable := enum.(CodecNamable); isNamable {
+			clsName = n.HessianCodecName()
+		}
+	}
+
+	return e.writeClassObject(clsName, []string{_enumNameField}, func(string) error {
+		_, err := e.writeString(name)
+		return err
+	})
+}
+
+// read the enum after the object tag
+func (d *Decoder) readEnum(typ reflect.Type, cls ClassDef) (interface{}, error) {
+	var name string
+	for _, fldName := range cls.FieldName {
+		var err error
+		if fldName == _enumNameField {
+			name, err = d.readString(_tagRead)
+		} else {
+			_, err = d.ReadData()
+		}
+		if err != nil {
+			return nil, newCodecError("readEnum", "failed to decode field '%s'", fldName, err)
+		}
+	}
+
+	v, err := enumValue(typ, name)
+	if err != nil {
+		return nil, err
+	}
+	d.addDecoderRef(v)
+	return v, nil
+}
+
+// read the enum after the type of hessian 1.0 map
+func (d *Decoder) readEnum1(typ reflect.Type, tag byte) (reflect.Value, error) {
+	var name string
+	for tag != _end1Flag {
+		fldName, err := d.readString(int32(tag))
+		if err != nil {
+			return _zeroValue, newCodecError("readEnum1", "read field name", err)
+		}
+		if fldName == _enumNameField {
+			name, err = d.readString(_tagRead)
+		} else {
+			_, err = d.readData1(_tagRead)
+		}
+		if err != nil {
+			return _zeroValue, newCodecError("readEnum1", "failed to decode field '%s'", fldName, err)
+		}
+
+		if tag, err = d.readTag(); err != nil {
+			return _zeroValue, newCodecError("readEnum1", err)
+		}
+	}
+
+	v, err := enumValue(typ, name)
+	if err != nil {
+		return _zeroValue, err
+	}
+	d.addDecoderRef(v)
+	return v, nil
+}
+
+// read the enum field, which may be a ref
+func (d *Decoder) readEnumField(dest reflect.Value) error {
+	v, err := d.ReadData()
+	if err != nil {
+		return err
+	}
+	if v != nil {
+		SetValue(dest, EnsureRawValue(v))
+	}
+	return nil
+}
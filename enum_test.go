@@ -0,0 +1,121 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package hessian
+
+import (
+	"bufio"
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type colorEnum string
+
+func (colorEnum) JavaEnumNames() []string { return []string{"RED", "GREEN", "BLUE"} }
+
+func (colorEnum) HessianCodecName() string { return "example.Color" }
+
+type levelEnum int32
+
+const (
+	levelLow levelEnum = iota
+	levelMiddle
+	levelHigh
+)
+
+func (levelEnum) JavaEnumNames() []string { return []string{"LOW", "MIDDLE", "HIGH"} }
+
+func (levelEnum) HessianCodecName() string { return "example.Level" }
+
+// the new version of colorEnum with more constants
+type colorEnumV2 string
+
+func (colorEnumV2) JavaEnumNames() []string { return []string{"RED", "GREEN", "BLUE", "PURPLE"} }
+
+func (colorEnumV2) HessianCodecName() string { return "example.Color" }
+
+func TestEnumEncode(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	e := NewEncoder(buf, nil)
+	for _, c := range []colorEnum{"RED", "GREEN", "BLUE", "GREEN"} {
+		assert.Nil(t, e.WriteObject(c))
+	}
+
+	// the same as java, see the enum example in object.go
+	assert.Equal(t, []byte{
+		'C', 0x0d, 'e', 'x', 'a', 'm', 'p', 'l', 'e', '.', 'C', 'o', 'l', 'o', 'r',
+		0x91, 0x04, 'n', 'a', 'm', 'e',
+		0x60, 0x03, 'R', 'E', 'D',
+		0x60, 0x05, 'G', 'R', 'E', 'E', 'N',
+		0x60, 0x04, 'B', 'L', 'U', 'E',
+		0x51, 0x91,
+	}, buf.Bytes())
+
+	d := NewDecoder(bufio.NewReader(buf), map[string]reflect.Type{"example.Color": reflect.TypeOf(colorEnum(""))})
+	for _, c := range []colorEnum{"RED", "GREEN", "BLUE", "GREEN"} {
+		decoded, err := d.ReadObject()
+		assert.Nil(t, err)
+		assert.Equal(t, c, decoded)
+	}
+}
+
+func TestEnumFields(t *testing.T) {
+	type enumFieldsT struct {
+		Color   colorEnum
+		Level   levelEnum
+		Current *levelEnum
+		Levels  map[string]levelEnum
+		Name    string
+	}
+	high := levelHigh
+	v := &enumFieldsT{
+		Color:   "BLUE",
+		Level:   levelHigh,
+		Current: &high,
+		Levels:  map[string]levelEnum{"a": levelMiddle},
+		Name:    "enum",
+	}
+	typMap, nameMap := ExtractTypeNameMap(v)
+
+	for _, version := range []int{ProtocolVersion2, ProtocolVersion1} {
+		bs, err := ToBytes(v, nameMap, WithProtocolVersion(version))
+		assert.Nil(t, err)
+		decoded, err := ToObject(bs, typMap, WithProtocolVersion(version))
+		assert.Nil(t, err)
+		assert.Equal(t, v, decoded)
+	}
+}
+
+func TestEnumUnknownConstant(t *testing.T) {
+	_, err := ToBytes(colorEnum("PINK"), nil)
+	assert.NotNil(t, err)
+	_, err = ToBytes(levelEnum(3), nil)
+	assert.NotNil(t, err)
+
+	// the constant added by the newer version is unknown for the older one
+	bs, err := ToBytes(colorEnumV2("PURPLE"), nil)
+	assert.Nil(t, err)
+	_, err = ToObject(bs, map[string]reflect.Type{"example.Color": reflect.TypeOf(colorEnum(""))})
+	assert.NotNil(t, err)
+
+	bs, err = ToBytes(colorEnumV2("RED"), nil)
+	assert.Nil(t, err)
+	decoded, err := ToObject(bs, map[string]reflect.Type{"example.Color": reflect.TypeOf(colorEnum(""))})
+	assert.Nil(t, err)
+	assert.Equal(t, colorEnum("RED"), decoded)
+}
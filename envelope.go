@@ -0,0 +1,141 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+// see: http://hessian.caucho.com/doc/hessian-ws.html
+//
+// Envelope Grammar
+//
+// envelope ::= 'E' string env-chunk 'Z'
+// env-chunk ::= int (string value)* binary int (string value)*
+//
+// An envelope wraps a hessian message for security, compression or other purposes.
+// The string is the name of the envelope, e.g. java class name com.caucho.hessian.io.Deflation.
+// The headers come before the body, and the footers come after it, both are preceded by the count.
+// The body is a binary which contains the wrapped message.
+//
+// -------------- Envelope examples
+//
+// E                 # envelope
+//   x0b Compression # name "Compression"
+//   x90             # no headers
+//   x23 x95 x96 x97 # binary body
+//   x90             # no footers
+//   Z
+
+package hessian
+
+const (
+	_envelopeTag = byte('E')
+)
+
+// Envelope a hessian 2.0 envelope which wraps the body with the headers and footers
+type Envelope struct {
+	Method  string
+	Headers map[string]interface{}
+	Body    []byte
+	Footers map[string]interface{}
+}
+
+// WriteEnvelope write the envelope, it's not supported by hessian 1.0
+func (e *Encoder) WriteEnvelope(env *Envelope) error {
+	if e.isHessian1() {
+		return newCodecError("WriteEnvelope", "envelope is not supported by hessian 1.0")
+	}
+
+	e.writeBT(_envelopeTag)
+	e.writeString(env.Method)
+	if err := e.writeEnvelopeEntries(env.Headers); err != nil {
+		return newCodecError("WriteEnvelope", "write headers", err)
+	}
+	e.writeBinary(env.Body)
+	if err := e.writeEnvelopeEntries(env.Footers); err != nil {
+		return newCodecError("WriteEnvelope", "write footers", err)
+	}
+	e.writeBT(_endFlag)
+	return nil
+}
+
+func (e *Encoder) writeEnvelopeEntries(entries map[string]interface{}) error {
+	e.writeInt(int32(len(entries)))
+	for k, v := range entries {
+		e.writeString(k)
+		if _, err := e.WriteData(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadEnvelope read the envelope, the version header before it is skipped
+func (d *Decoder) ReadEnvelope() (*Envelope, error) {
+	tag, err := d.readVersion()
+	if err != nil {
+		return nil, newCodecError("ReadEnvelope", err)
+	}
+	if tag != _envelopeTag {
+		return nil, newCodecError("ReadEnvelope", "error envelope tag: 0x%x", tag)
+	}
+	return d.readEnvelope()
+}
+
+// readEnvelope read the envelope after the tag 'E'
+func (d *Decoder) readEnvelope() (*Envelope, error) {
+	method, err := d.readString(_tagRead)
+	if err != nil {
+		return nil, newCodecError("readEnvelope", "read method", err)
+	}
+	env := &Envelope{Method: method}
+	if env.Headers, err = d.readEnvelopeEntries(); err != nil {
+		return nil, newCodecError("readEnvelope", "read headers", err)
+	}
+	if env.Body, err = d.readBinary(_tagRead); err != nil {
+		return nil, newCodecError("readEnvelope", "read body", err)
+	}
+	if env.Footers, err = d.readEnvelopeEntries(); err != nil {
+		return nil, newCodecError("readEnvelope", "read footers", err)
+	}
+
+	tag, err := d.readTag()
+	if err != nil {
+		return nil, newCodecError("readEnvelope", err)
+	}
+	if tag != _endFlag {
+		return nil, newCodecError("readEnvelope", "error envelope end tag: 0x%x", tag)
+	}
+	return env, nil
+}
+
+// read the entries preceded by the count, nil is returned if no entry
+func (d *Decoder) readEnvelopeEntries() (map[string]interface{}, error) {
+	count, err := d.readInt(_tagRead)
+	if err != nil {
+		return nil, err
+	}
+	if count <= 0 {
+		return nil, nil
+	}
+
+	entries := make(map[string]interface{}, count)
+	for i := 0; i < int(count); i++ {
+		key, err := d.readString(_tagRead)
+		if err != nil {
+			return nil, err
+		}
+		if entries[key], err = d.ReadObject(); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package hessian
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvelope(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	e := NewEncoder(buf, nil)
+	assert.Nil(t, e.WriteEnvelope(&Envelope{Method: "Compression", Body: []byte{0x95, 0x96, 0x97}}))
+	assert.Equal(t, []byte{
+		'E', 0x0b, 'C', 'o', 'm', 'p', 'r', 'e', 's', 's', 'i', 'o', 'n',
+		0x90,
+		0x23, 0x95, 0x96, 0x97,
+		0x90,
+		'Z',
+	}, buf.Bytes())
+
+	env, err := NewDecoder(bufio.NewReader(buf), nil).ReadEnvelope()
+	assert.Nil(t, err)
+	assert.Equal(t, &Envelope{Method: "Compression", Body: []byte{0x95, 0x96, 0x97}}, env)
+}
+
+func TestEnvelopeHeaders(t *testing.T) {
+	expect := &Envelope{
+		Method:  "Signature",
+		Headers: map[string]interface{}{"algorithm": "sha1"},
+		Body:    []byte{1, 2},
+		Footers: map[string]interface{}{"signature": []byte{3, 4}, "length": int32(2)},
+	}
+
+	buf := bytes.NewBuffer([]byte{_versionTag, _versionMajor, _versionMinor})
+	assert.Nil(t, NewEncoder(buf, nil).WriteEnvelope(expect))
+
+	env, err := NewDecoder(bufio.NewReader(buf), nil).ReadEnvelope()
+	assert.Nil(t, err)
+	assert.Equal(t, expect, env)
+
+	err = NewEncoder(buf, nil, WithProtocolVersion(ProtocolVersion1)).WriteEnvelope(expect)
+	assert.NotNil(t, err)
+}
@@ -27,6 +27,11 @@ type CodecErr struct {
 	Err     error
 }
 
+// Unwrap return the cause of the error
+func (e CodecErr) Unwrap() error {
+	return e.Err
+}
+
 func (e CodecErr) Error() string {
 	if e.Err == nil {
 		return e.Message
@@ -0,0 +1,172 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package hessian
+
+import (
+	"reflect"
+	"sync"
+)
+
+// EncodeFunc encode the value of the registered type or its pointer by the encoder,
+// e.g. write a *Object with the java class name and fields.
+type EncodeFunc func(e *Encoder, v interface{}) error
+
+// DecodeFunc decode the value read by the decoder into the value of the registered type,
+// v is a *Object for an object of the registered java class, a map[interface{}]interface{} for a typed map of the class,
+// or the value read for a field of the registered go type.
+type DecodeFunc func(d *Decoder, v interface{}) (interface{}, error)
+
+type _customSerializer struct {
+	encode EncodeFunc
+	decode DecodeFunc
+}
+
+// SerializerFactory the registry of the custom serializers by the java class name and the go type,
+// which is shared by the encoders and decoders with the option WithSerializerFactory.
+//
+//	factory := hessian.NewSerializerFactory()
+//	factory.Register("java.util.Locale", reflect.TypeOf(Locale{}), encodeLocale, decodeLocale)
+//	serializer := hessian.NewSerializer(typeMap, nameMap, hessian.WithSerializerFactory(factory))
+type SerializerFactory struct {
+	lock    sync.RWMutex
+	types   map[reflect.Type]*_customSerializer
+	classes map[string]*_customSerializer
+}
+
+// NewSerializerFactory create an empty serializer factory
+func NewSerializerFactory() *SerializerFactory {
+	return &SerializerFactory{
+		types:   make(map[reflect.Type]*_customSerializer),
+		classes: make(map[string]*_customSerializer),
+	}
+}
+
+// Register register the custom serializer, the values of the go type typ or its pointer are encoded by encode,
+// and the objects of the java class className and the fields of the go type are decoded by decode.
+// The className or typ is not registered if it's empty, and the encode or decode is ignored if it's nil.
+func (f *SerializerFactory) Register(className string, typ reflect.Type, encode EncodeFunc, decode DecodeFunc) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	s := &_customSerializer{encode: encode, decode: decode}
+	if typ != nil {
+		f.types[UnpackPtrType(typ)] = s
+	}
+	if className != "" {
+		f.classes[className] = s
+	}
+}
+
+// findType find the serializer of the type or the element type of the pointer
+func (f *SerializerFactory) findType(typ reflect.Type) (*_customSerializer, bool) {
+	if f == nil {
+		return nil, false
+	}
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	s, ok := f.types[UnpackPtrType(typ)]
+	return s, ok
+}
+
+// findClass find the serializer of the java class
+func (f *SerializerFactory) findClass(className string) (*_customSerializer, bool) {
+	if f == nil {
+		return nil, false
+	}
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	s, ok := f.classes[className]
+	return s, ok
+}
+
+// writeCustom write the value by the custom encode function if registered for its type
+func (e *Encoder) writeCustom(data interface{}) (bool, int, error) {
+	s, ok := e.serializerFactory.findType(reflect.TypeOf(data))
+	if !ok || s.encode == nil {
+		return false, 0, nil
+	}
+	v := reflect.ValueOf(data)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			n, err := e.writeBT(_nilTag)
+			return true, n, err
+		}
+		kind, addr := refAddr(v)
+		if elem, ok := e.refMap[addr]; ok && elem.kind == kind {
+			n, err := e.writeRef(elem.index)
+			return true, n, err
+		}
+	}
+
+	index := e.refCount
+	if err := s.encode(e, data); err != nil {
+		return true, 0, newCodecError("writeCustom", "encode %T", data, err)
+	}
+	if v.Kind() == reflect.Ptr && e.refCount > index {
+		// the pointer refers to the list, map or object written for it
+		e.aliasRef(v, index)
+	}
+	return true, 1, nil
+}
+
+// readCustomObject read the object of the class definition by the custom decode function if registered for the class,
+// the refs to the object are resolved to the decoded value.
+func (d *Decoder) readCustomObject(cls ClassDef, read func() (interface{}, error)) (bool, interface{}, error) {
+	s, ok := d.serializerFactory.findClass(cls.FullClassName)
+	if !ok || s.decode == nil {
+		return false, nil, nil
+	}
+
+	index := len(d.refList)
+	o, err := read()
+	if err != nil {
+		return true, nil, err
+	}
+	v, err := s.decode(d, o)
+	if err != nil {
+		return true, nil, newCodecError("readCustomObject", "decode %s", cls.FullClassName, err)
+	}
+	if index < len(d.refList) {
+		if v == nil {
+			// the refs to the nil result are resolved to null
+			d.refList[index] = reflect.Zero(_interfaceSliceType.Elem())
+		} else {
+			d.refList[index] = reflect.ValueOf(v)
+		}
+	}
+	return true, v, nil
+}
+
+// readCustomField read the field by the custom decode function if registered for the type of the field,
+// the value decoded for the java class is set directly.
+func (d *Decoder) readCustomField(dest reflect.Value) (bool, error) {
+	s, ok := d.serializerFactory.findType(dest.Type())
+	if !ok || s.decode == nil {
+		return false, nil
+	}
+
+	v, err := d.ReadObject()
+	if err != nil || v == nil {
+		return true, err
+	}
+	if UnpackPtrType(reflect.TypeOf(v)) != UnpackPtrType(dest.Type()) {
+		if v, err = s.decode(d, v); err != nil {
+			return true, newCodecError("readCustomField", "decode %v", dest.Type(), err)
+		}
+	}
+	SetValue(dest, reflect.ValueOf(v))
+	return true, nil
+}
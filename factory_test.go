@@ -0,0 +1,161 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package hessian
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// the java.util.Locale is written as the object of com.caucho.hessian.io.LocaleHandle
+const _localeHandleClass = "com.caucho.hessian.io.LocaleHandle"
+
+type localeT struct {
+	Language string
+	Country  string
+}
+
+type profileT struct {
+	Name     string
+	Locale   localeT
+	Fallback *localeT
+	Other    *localeT
+}
+
+func encodeLocale(e *Encoder, v interface{}) error {
+	l := UnpackPtrValue(reflect.ValueOf(v)).Interface().(localeT)
+	o := NewObject(_localeHandleClass)
+	o.Set("value", l.Language+"_"+l.Country)
+	_, err := e.WriteData(o)
+	return err
+}
+
+func decodeLocale(d *Decoder, v interface{}) (interface{}, error) {
+	var value interface{}
+	switch o := v.(type) {
+	case *Object:
+		value, _ = o.Get("value")
+	case map[interface{}]interface{}:
+		value = o["value"]
+	}
+	s, ok := value.(string)
+	if !ok {
+		return nil, errors.New("invalid locale")
+	}
+	parts := strings.SplitN(s, "_", 2)
+	l := &localeT{Language: parts[0]}
+	if len(parts) > 1 {
+		l.Country = parts[1]
+	}
+	return l, nil
+}
+
+func TestSerializerFactory(t *testing.T) {
+	factory := NewSerializerFactory()
+	factory.Register(_localeHandleClass, reflect.TypeOf(localeT{}), encodeLocale, decodeLocale)
+
+	locale := &localeT{Language: "en", Country: "US"}
+	v := &profileT{Name: "tom", Locale: localeT{Language: "zh", Country: "CN"}, Fallback: locale, Other: locale}
+	nameMap := map[string]string{"profileT": "test.Profile"}
+	typMap := map[string]reflect.Type{"test.Profile": reflect.TypeOf(profileT{})}
+
+	for _, version := range []int{ProtocolVersion2, ProtocolVersion1} {
+		s := NewSerializer(typMap, nameMap, WithProtocolVersion(version), WithSerializerFactory(factory))
+		bs, err := s.ToBytes(v)
+		assert.Nil(t, err)
+
+		// the locale is written as the java LocaleHandle
+		o, err := ToObject(bs, nil, WithDynamicObject())
+		assert.Nil(t, err)
+		value, _ := o.(*Object).Get("locale")
+		assert.Equal(t, &Object{ClassName: _localeHandleClass, Fields: []string{"value"}, Values: []interface{}{"zh_CN"}}, value)
+
+		decoded, err := s.ToObject(bs)
+		assert.Nil(t, err)
+		assert.Equal(t, v, decoded)
+		assert.True(t, decoded.(*profileT).Fallback == decoded.(*profileT).Other)
+
+		// the top level object of the class
+		bs, err = s.ToBytes([]interface{}{locale, locale})
+		assert.Nil(t, err)
+		decoded, err = s.ToObject(bs)
+		assert.Nil(t, err)
+		assert.Equal(t, []interface{}{locale, locale}, decoded)
+		assert.True(t, decoded.([]interface{})[0] == decoded.([]interface{})[1])
+	}
+
+	bs, err := ToBytes((*localeT)(nil), nil, WithSerializerFactory(factory))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{_nilTag}, bs)
+}
+
+func TestSerializerFactoryTypedMap(t *testing.T) {
+	factory := NewSerializerFactory()
+	factory.Register(_localeHandleClass, nil, nil, decodeLocale)
+
+	buf := bytes.NewBuffer(nil)
+	e := NewEncoder(buf, nil)
+	e.writeBT(_mapTypedTag)
+	e.writeString(_localeHandleClass)
+	e.writeString("value")
+	e.writeString("fr")
+	e.writeBT(_endFlag)
+
+	decoded, err := ToObject(buf.Bytes(), nil, WithSerializerFactory(factory))
+	assert.Nil(t, err)
+	assert.Equal(t, &localeT{Language: "fr"}, decoded)
+
+	// the encode function is not registered
+	bs, err := ToBytes(localeT{Language: "fr"}, nil, WithSerializerFactory(factory))
+	assert.Nil(t, err)
+	o, err := ToObject(bs, nil, WithDynamicObject())
+	assert.Nil(t, err)
+	assert.Equal(t, "localeT", o.(*Object).ClassName)
+}
+
+func TestSerializerFactoryNilResult(t *testing.T) {
+	factory := NewSerializerFactory()
+	factory.Register(_localeHandleClass, reflect.TypeOf(localeT{}), encodeLocale, func(d *Decoder, v interface{}) (interface{}, error) {
+		return nil, nil
+	})
+
+	locale := &localeT{Language: "en", Country: "US"}
+	v := &profileT{Name: "tom", Fallback: locale, Other: locale}
+	nameMap := map[string]string{"profileT": "test.Profile"}
+	typMap := map[string]reflect.Type{"test.Profile": reflect.TypeOf(profileT{})}
+
+	for _, version := range []int{ProtocolVersion2, ProtocolVersion1} {
+		s := NewSerializer(typMap, nameMap, WithProtocolVersion(version), WithSerializerFactory(factory))
+
+		// the other locale is a ref to the nil result of the fallback
+		bs, err := s.ToBytes(v)
+		assert.Nil(t, err)
+		decoded, err := s.ToObject(bs)
+		assert.Nil(t, err)
+		assert.Equal(t, &profileT{Name: "tom"}, decoded)
+
+		bs, err = s.ToBytes([]interface{}{locale, locale, "after"})
+		assert.Nil(t, err)
+		decoded, err = s.ToObject(bs)
+		assert.Nil(t, err)
+		assert.Equal(t, []interface{}{nil, nil, "after"}, decoded)
+	}
+}
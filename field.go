@@ -0,0 +1,302 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+// Struct fields.
+//
+// The exported fields of a struct are encoded and decoded, the unexported fields are skipped.
+// The java field name is converted from the go field name by the naming strategy,
+// which is the go field name with the first letter in lower case by default, see WithNamingStrategy.
+// It can be specified by the tag `hessian:"name"`, a field with the tag `hessian:"-"` is skipped.
+// The json tag is used if there is no hessian tag with the option WithJSONTag.
+//
+// The tag options after the name:
+//
+// omitempty  the field with an empty string, slice, map, nil pointer or nil interface is written as null,
+//            for the fields of an object are defined by the class definition in hessian 2.0,
+//            and the field is not written in hessian 1.0.
+// type=name  the java class name of the list or map value, which takes precedence over the name map.
+//
+// The fields of an anonymous embedded struct without tag name are flattened into the fields of the parent,
+// as the fields of the super class in java. The same as the java serializer,
+// the fields declared by the struct are in front of the fields of the embedded structs.
+// A field shadowed by a field with the same name is not dropped, as java does for the field of a super class
+// with the same name as a field of the sub class. The fields with the same name are all written in the class
+// definition in order, and the n-th field with the name is decoded from the n-th value with the name,
+// the values with the name more than the fields are skipped.
+// With the option WithDisallowShadowedFields, an error is returned for the struct with shadowed fields instead.
+//
+// type Car struct {
+//   URL      string   `hessian:"url"`
+//   IsActive bool     `hessian:"is_active"`
+//   Tags     []string `hessian:"tags,omitempty,type=java.util.LinkedList"`
+//   Cache    string   `hessian:"-"`
+//   owner    string
+// }
+
+package hessian
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+const (
+	_hessianTag = "hessian"
+	_jsonTag    = "json"
+)
+
+// the struct field to encode and decode
+type _structField struct {
+	index     []int
+	goName    string
+	name      string
+	tagged    bool
+	omitEmpty bool
+	typeName  string
+
+	// shadowed by a field with the same name in front of it
+	shadowed bool
+}
+
+type _structFieldsKey struct {
+	typ     reflect.Type
+	jsonTag bool
+}
+
+// structFields return the fields of the struct type to encode and decode,
+// an error is returned for the shadowed fields with the option WithDisallowShadowedFields.
+func (o *options) structFields(typ reflect.Type) ([]_structField, error) {
+	naming := o.naming
+	if naming == nil {
+		naming = LowerCamelNaming
+	}
+	key := _structFieldsKey{typ, o.jsonTag}
+	cached, ok := naming.fields.Load(key)
+	if !ok {
+		cached = newStructFields(o.appendStructFields(nil, typ, nil, naming, map[reflect.Type]bool{typ: true}))
+		naming.fields.Store(key, cached)
+	}
+
+	fields := cached.([]_structField)
+	if o.disallowShadowedFields {
+		for i := range fields {
+			if fields[i].shadowed {
+				return nil, newCodecError("structFields", "%v: field %s shadowed by the field with the same name %s",
+					typ, fields[i].goName, fields[i].name)
+			}
+		}
+	}
+	return fields, nil
+}
+
+// newStructFields mark the fields shadowed by the fields with the same name in front of them,
+// the fields with the same name are all kept, see the rule of the shadowed fields above.
+func newStructFields(fields []_structField) []_structField {
+	names := make(map[string]bool, len(fields))
+	for i := range fields {
+		fields[i].shadowed = names[fields[i].name]
+		names[fields[i].name] = true
+	}
+	return fields
+}
+
+// append the fields of the struct type, the fields of the anonymous embedded structs are appended after
+// the fields declared by the struct, visited is the embedded types in the path to avoid recursion.
+func (o *options) appendStructFields(fields []_structField, typ reflect.Type, parent []int,
+	naming *NamingStrategy, visited map[reflect.Type]bool) []_structField {
+	var embedded []_structField
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		tag, ok := sf.Tag.Lookup(_hessianTag)
+		if !ok && o.jsonTag {
+			tag = sf.Tag.Get(_jsonTag)
+		}
+		if tag == "-" {
+			continue
+		}
+
+		opts := strings.Split(tag, ",")
+		index := append(append(make([]int, 0, len(parent)+1), parent...), i)
+		if sf.Anonymous && opts[0] == "" && isEmbeddedStruct(sf) {
+			embedded = append(embedded, _structField{index: index, goName: sf.Name})
+			continue
+		}
+		if sf.PkgPath != "" {
+			// unexported
+			continue
+		}
+
+		f := _structField{index: index, goName: sf.Name, name: opts[0], tagged: opts[0] != ""}
+		for _, opt := range opts[1:] {
+			switch {
+			case opt == "omitempty":
+				f.omitEmpty = true
+			case strings.HasPrefix(opt, "type="):
+				f.typeName = strings.TrimPrefix(opt, "type=")
+			}
+		}
+		if f.name == "" {
+			f.name = naming.FieldName(sf.Name)
+		}
+		fields = append(fields, f)
+	}
+
+	for _, em := range embedded {
+		ft := UnpackPtrType(typ.FieldByIndex(em.index[len(parent):]).Type)
+		if visited[ft] {
+			continue
+		}
+		visited[ft] = true
+		fields = o.appendStructFields(fields, ft, em.index, naming, visited)
+		delete(visited, ft)
+	}
+	return fields
+}
+
+// isEmbeddedStruct check whether the anonymous field is a struct to flatten,
+// the types encoded specially and the unexported struct pointers which can't be allocated are not flattened.
+func isEmbeddedStruct(sf reflect.StructField) bool {
+	typ := sf.Type
+	if typ.Kind() == reflect.Ptr {
+		if sf.PkgPath != "" {
+			return false
+		}
+		typ = typ.Elem()
+	}
+	return typ.Kind() == reflect.Struct && typ != _dateType && typ != _objectType && !isBigNumberType(typ)
+}
+
+// fieldByIndex return the nested field of the struct value by the index path,
+// the nil embedded pointers are allocated if alloc is true, otherwise an invalid value is returned.
+func fieldByIndex(v reflect.Value, index []int, alloc bool) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc {
+					return reflect.Value{}
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// readStructField read the struct field by the index path,
+// the nil embedded pointers in the path are allocated only if the value is not zero.
+func (d *Decoder) readStructField(fldName string, st reflect.Value, index []int) error {
+	if fv := fieldByIndex(st, index, false); fv.IsValid() {
+		if !fv.CanSet() {
+			return newCodecError("readStructField", "field %s can't set", fldName)
+		}
+		return d.readField(fldName, fv)
+	}
+
+	fv := reflect.New(st.Type().FieldByIndex(index).Type).Elem()
+	if err := d.readField(fldName, fv); err != nil {
+		return err
+	}
+	if !fv.IsZero() {
+		fieldByIndex(st, index, true).Set(fv)
+	}
+	return nil
+}
+
+// the java field names of the struct fields
+func structFieldNames(fields []_structField) []string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.name
+	}
+	return names
+}
+
+// findField find the index path of the n-th struct field with the java field name,
+// the go name of the field without tag name is also matched.
+func (o *options) findField(name string, typ reflect.Type, nth int) ([]int, error) {
+	fields, err := o.structFields(typ)
+	if err != nil {
+		return nil, err
+	}
+	match := func(matched func(f *_structField) bool) ([]int, bool) {
+		n := nth
+		for i := range fields {
+			if matched(&fields[i]) {
+				if n == 0 {
+					return fields[i].index, true
+				}
+				n--
+			}
+		}
+		return nil, false
+	}
+
+	if index, ok := match(func(f *_structField) bool { return f.name == name }); ok {
+		return index, nil
+	}
+
+	capitalName := capitalizeName(name)
+	if index, ok := match(func(f *_structField) bool {
+		return !f.tagged && (f.goName == name || f.goName == capitalName)
+	}); ok {
+		return index, nil
+	}
+
+	if o.caseInsensitive {
+		if index, ok := match(func(f *_structField) bool {
+			return strings.EqualFold(f.name, name) || (!f.tagged && strings.EqualFold(f.goName, name))
+		}); ok {
+			return index, nil
+		}
+	}
+	return nil, errors.New("no field " + name)
+}
+
+// isEmptyValue check whether the value of omitempty field is empty
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
+
+// write the value of the struct field
+func (e *Encoder) writeFieldValue(fv reflect.Value, f *_structField) error {
+	var err error
+	switch {
+	case !fv.IsValid():
+		// the field of nil embedded struct
+		_, err = e.writeBT(_nilTag)
+	case f.omitEmpty && isEmptyValue(fv):
+		_, err = e.writeBT(_nilTag)
+	case fv.CanAddr() && fv.Kind() != reflect.Ptr && fv.Addr().Type().Implements(_marshalerType):
+		// the pointer receiver of the addressable field
+		_, err = e.writeMarshaler(fv.Addr().Interface().(HessianMarshaler))
+	case f.typeName != "" && (fv.Kind() == reflect.Slice || fv.Kind() == reflect.Array):
+		_, err = e.writeListType(fv.Interface(), f.typeName)
+	case f.typeName != "" && fv.Kind() == reflect.Map:
+		_, err = e.writeMapType(fv.Interface(), f.typeName)
+	default:
+		_, err = e.WriteData(fv.Interface())
+	}
+	return err
+}
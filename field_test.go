@@ -0,0 +1,298 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package hessian
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type taggedFieldsT struct {
+	URL      string   `hessian:"url"`
+	IsActive bool     `hessian:"is_active"`
+	ID       int32    `hessian:",omitempty"`
+	Tags     []string `hessian:"tags,omitempty,type=java.util.LinkedList"`
+	Cache    string   `hessian:"-"`
+	Remark   *string  `hessian:"remark,omitempty"`
+	secret   string
+}
+
+type jsonFieldsT struct {
+	UserName string `json:"user_name"`
+	Age      int32  `json:"age,omitempty" hessian:"years"`
+	Token    string `json:"-"`
+	Nick     string `json:",omitempty"`
+}
+
+func dynamicFields(t *testing.T, bs []byte, opts ...Option) map[string]interface{} {
+	decoded, err := ToObject(bs, nil, append(opts, WithDynamicObject())...)
+	assert.Nil(t, err)
+	o, ok := decoded.(*Object)
+	if !assert.True(t, ok, "expect *Object but get %v", decoded) {
+		return nil
+	}
+	return o.Map()
+}
+
+func TestStructFieldTags(t *testing.T) {
+	v := &taggedFieldsT{URL: "http://a", IsActive: true, ID: 1, Tags: []string{"a"}, Cache: "c", secret: "s"}
+	nameMap := map[string]string{"taggedFieldsT": "test.Tagged"}
+	typMap := map[string]reflect.Type{"test.Tagged": reflect.TypeOf(taggedFieldsT{})}
+
+	for _, version := range []int{ProtocolVersion2, ProtocolVersion1} {
+		bs, err := ToBytes(v, nameMap, WithProtocolVersion(version))
+		assert.Nil(t, err)
+
+		fields := map[string]interface{}{
+			"url":       "http://a",
+			"is_active": true,
+			"iD":        int32(1),
+			"tags":      []interface{}{"a"},
+		}
+		if version == ProtocolVersion2 {
+			fields["remark"] = nil
+		}
+		assert.Equal(t, fields, dynamicFields(t, bs, WithProtocolVersion(version)))
+
+		decoded, err := ToObject(bs, typMap, WithProtocolVersion(version))
+		assert.Nil(t, err)
+		assert.Equal(t, &taggedFieldsT{URL: "http://a", IsActive: true, ID: 1, Tags: []string{"a"}}, decoded)
+	}
+
+	// the type of list field
+	bs, err := ToBytes(v, nameMap)
+	assert.Nil(t, err)
+	assert.True(t, bytes.Contains(bs, []byte("java.util.LinkedList")))
+
+	// the empty fields are written as null in hessian 2.0, and are not written in hessian 1.0
+	empty := &taggedFieldsT{URL: "http://a"}
+	bs, err = ToBytes(empty, nameMap)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"url":       "http://a",
+		"is_active": false,
+		"iD":        int32(0),
+		"tags":      nil,
+		"remark":    nil,
+	}, dynamicFields(t, bs))
+
+	bs, err = ToBytes(empty, nameMap, WithProtocolVersion(ProtocolVersion1))
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"url":       "http://a",
+		"is_active": false,
+		"iD":        int32(0),
+	}, dynamicFields(t, bs, WithProtocolVersion(ProtocolVersion1)))
+}
+
+func TestStructFieldJSONTag(t *testing.T) {
+	v := &jsonFieldsT{UserName: "tom", Age: 3, Token: "t", Nick: "n"}
+	nameMap := map[string]string{"jsonFieldsT": "test.JSON"}
+	typMap := map[string]reflect.Type{"test.JSON": reflect.TypeOf(jsonFieldsT{})}
+
+	bs, err := ToBytes(v, nameMap)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"userName": "tom",
+		"years":    int32(3),
+		"token":    "t",
+		"nick":     "n",
+	}, dynamicFields(t, bs))
+
+	bs, err = ToBytes(v, nameMap, WithJSONTag())
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"user_name": "tom",
+		"years":     int32(3),
+		"nick":      "n",
+	}, dynamicFields(t, bs))
+
+	decoded, err := ToObject(bs, typMap, WithJSONTag())
+	assert.Nil(t, err)
+	assert.Equal(t, &jsonFieldsT{UserName: "tom", Age: 3, Nick: "n"}, decoded)
+}
+
+func TestSkipUnknownFields(t *testing.T) {
+	typMap := map[string]reflect.Type{"test.Tagged": reflect.TypeOf(taggedFieldsT{})}
+
+	// the values of the unknown, skipped and unexported fields are skipped
+	o := &Object{
+		ClassName: "test.Tagged",
+		Fields:    []string{"cache", "url", "unknown", "is_active", "secret", "iD"},
+		Values:    []interface{}{"c", "http://a", []interface{}{int32(1), "a"}, true, "s", int32(3)},
+	}
+	for _, version := range []int{ProtocolVersion2, ProtocolVersion1} {
+		bs, err := ToBytes(o, nil, WithProtocolVersion(version))
+		assert.Nil(t, err)
+		decoded, err := ToObject(bs, typMap, WithProtocolVersion(version))
+		assert.Nil(t, err)
+		assert.Equal(t, &taggedFieldsT{URL: "http://a", IsActive: true, ID: 3}, decoded)
+	}
+}
+
+func TestFindField(t *testing.T) {
+	typ := reflect.TypeOf(taggedFieldsT{})
+	o := &options{}
+	fields, err := o.structFields(typ)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"url", "is_active", "iD", "tags", "remark"}, structFieldNames(fields))
+
+	for name, index := range map[string]int{"url": 0, "is_active": 1, "iD": 2, "ID": 2, "tags": 3} {
+		i, err := o.findField(name, typ, 0)
+		assert.Nil(t, err, name)
+		assert.Equal(t, []int{index}, i, name)
+	}
+	for _, name := range []string{"URL", "isActive", "cache", "secret", "Tags"} {
+		_, err := o.findField(name, typ, 0)
+		assert.NotNil(t, err, name)
+	}
+}
+
+type baseEntityT struct {
+	ID      int64
+	Version int32
+	remark  string
+}
+
+type AuditT struct {
+	Creator string
+	Version string
+}
+
+type userEntityT struct {
+	*AuditT
+	baseEntityT
+	Name string
+}
+
+type cyclicT struct {
+	*cyclicT
+	Name string
+}
+
+func TestEmbeddedStructFields(t *testing.T) {
+	fields, err := (&options{}).structFields(reflect.TypeOf(userEntityT{}))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"name", "creator", "version", "iD", "version"}, structFieldNames(fields))
+	assert.Equal(t, []int{0, 1}, fields[2].index)
+	assert.Equal(t, []int{1, 1}, fields[4].index)
+	assert.False(t, fields[2].shadowed)
+	assert.True(t, fields[4].shadowed)
+
+	fields, err = (&options{}).structFields(reflect.TypeOf(cyclicT{}))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"name"}, structFieldNames(fields))
+
+	o := &options{}
+	typ := reflect.TypeOf(userEntityT{})
+	for _, c := range []struct {
+		name  string
+		nth   int
+		index []int
+	}{
+		{"version", 0, []int{0, 1}},
+		{"version", 1, []int{1, 1}},
+		{"ID", 0, []int{1, 0}},
+		{"name", 0, []int{2}},
+	} {
+		index, err := o.findField(c.name, typ, c.nth)
+		assert.Nil(t, err)
+		assert.Equal(t, c.index, index)
+	}
+	_, err = o.findField("version", typ, 2)
+	assert.NotNil(t, err)
+}
+
+func TestEmbeddedStruct(t *testing.T) {
+	nameMap := map[string]string{"userEntityT": "test.User"}
+	typMap := map[string]reflect.Type{"test.User": reflect.TypeOf(userEntityT{})}
+
+	for _, version := range []int{ProtocolVersion2, ProtocolVersion1} {
+		v := &userEntityT{
+			AuditT:      &AuditT{Creator: "admin", Version: "v1"},
+			baseEntityT: baseEntityT{ID: 1, Version: 2, remark: "r"},
+			Name:        "tom",
+		}
+		bs, err := ToBytes(v, nameMap, WithProtocolVersion(version))
+		assert.Nil(t, err)
+		decoded, err := ToObject(bs, typMap, WithProtocolVersion(version))
+		assert.Nil(t, err)
+		v.remark = ""
+		assert.Equal(t, v, decoded)
+
+		v = &userEntityT{baseEntityT: baseEntityT{ID: 1}, Name: "tom"}
+		bs, err = ToBytes(v, nameMap, WithProtocolVersion(version))
+		assert.Nil(t, err)
+		decoded, err = ToObject(bs, typMap, WithProtocolVersion(version))
+		assert.Nil(t, err)
+		assert.Equal(t, v, decoded)
+	}
+
+	// the n-th value of the duplicate name is decoded to the n-th field with the name,
+	// and the values more than the fields are skipped
+	for _, version := range []int{ProtocolVersion2, ProtocolVersion1} {
+		bs, err := ToBytes(&Object{
+			ClassName: "test.User",
+			Fields:    []string{"name", "version", "creator", "version", "iD", "version"},
+			Values:    []interface{}{"tom", "v1", "admin", int32(2), int64(1), int32(3)},
+		}, nil, WithProtocolVersion(version))
+		assert.Nil(t, err)
+		decoded, err := ToObject(bs, typMap, WithProtocolVersion(version))
+		assert.Nil(t, err)
+		assert.Equal(t, &userEntityT{
+			AuditT:      &AuditT{Creator: "admin", Version: "v1"},
+			baseEntityT: baseEntityT{ID: 1, Version: 2},
+			Name:        "tom",
+		}, decoded)
+	}
+
+	bs, err := ToBytes(&userEntityT{AuditT: &AuditT{Creator: "admin", Version: "v1"}, baseEntityT: baseEntityT{Version: 2}, Name: "tom"}, nameMap)
+	assert.Nil(t, err)
+	o, err := ToObject(bs, nil, WithDynamicObject())
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"name", "creator", "version", "iD", "version"}, o.(*Object).Fields)
+	assert.Equal(t, []interface{}{"tom", "admin", "v1", int64(0), int32(2)}, o.(*Object).Values)
+}
+
+func TestDisallowShadowedFields(t *testing.T) {
+	nameMap := map[string]string{"userEntityT": "test.User"}
+	typMap := map[string]reflect.Type{"test.User": reflect.TypeOf(userEntityT{})}
+	v := &userEntityT{AuditT: &AuditT{Version: "v1"}, baseEntityT: baseEntityT{Version: 2}, Name: "tom"}
+
+	for _, version := range []int{ProtocolVersion2, ProtocolVersion1} {
+		_, err := ToBytes(v, nameMap, WithProtocolVersion(version), WithDisallowShadowedFields())
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "field Version shadowed")
+
+		bs, err := ToBytes(v, nameMap, WithProtocolVersion(version))
+		assert.Nil(t, err)
+		_, err = ToObject(bs, typMap, WithProtocolVersion(version), WithDisallowShadowedFields())
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "field Version shadowed")
+	}
+
+	// no error for the struct without shadowed fields
+	p := &P{X: 1, Name: "p"}
+	typMap, nameMap = ExtractTypeNameMap(p)
+	bs, err := ToBytes(p, nameMap, WithDisallowShadowedFields())
+	assert.Nil(t, err)
+	decoded, err := ToObject(bs, typMap, WithDisallowShadowedFields())
+	assert.Nil(t, err)
+	assert.Equal(t, p, decoded)
+}
@@ -0,0 +1,99 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package hessian
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"reflect"
+)
+
+// Marshal [NO-CACHE API] serialize the value of T, the name map is extracted from the value
+func Marshal[T any](v T, opts ...Option) ([]byte, error) {
+	_, nameMap := ExtractTypeNameMap(v)
+	return ToBytes(v, nameMap, opts...)
+}
+
+// UnmarshalAs [NO-CACHE API] deserialize bytes to a value of T, the type map is built from T
+func UnmarshalAs[T any](data []byte, opts ...Option) (T, error) {
+	typMap, _ := typeNameMapOf[T]()
+	var v T
+	d := NewDecoder(bufio.NewReader(bytes.NewReader(data)), typMap, opts...)
+	err := d.DecodeInto(&v)
+	return v, err
+}
+
+// typeNameMapOf build the type map and name map of T
+func typeNameMapOf[T any]() (map[string]reflect.Type, map[string]string) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	typMap, nameMap := ExtractTypeNameMap(reflect.New(UnpackPtrType(typ)).Interface())
+	for name, t := range TypeMapOf(typ) {
+		if _, ok := typMap[name]; !ok {
+			typMap[name] = t
+		}
+	}
+	return typMap, nameMap
+}
+
+// Codec the typed serializer of T, a composite of encoder and decoder,
+// whose type map and name map are built from T. It's not safe for concurrent use.
+type Codec[T any] struct {
+	encoder *Encoder
+	decoder *Decoder
+}
+
+// NewCodec create the typed serializer of T
+func NewCodec[T any](opts ...Option) *Codec[T] {
+	typMap, nameMap := typeNameMapOf[T]()
+	return &Codec[T]{
+		encoder: NewEncoder(nil, nameMap, opts...),
+		decoder: NewDecoder(nil, typMap, opts...),
+	}
+}
+
+// WriteTo write the value to writer
+func (c *Codec[T]) WriteTo(w io.Writer, v T) error {
+	return c.encoder.WriteTo(w, v)
+}
+
+// Write write the value to writer continuously, it must be called after calling Codec.WriteTo
+func (c *Codec[T]) Write(v T) error {
+	return c.encoder.WriteObject(v)
+}
+
+// ToBytes convert the value to bytes
+func (c *Codec[T]) ToBytes(v T) ([]byte, error) {
+	return c.encoder.Encode(v)
+}
+
+// ReadFrom read the value from reader
+func (c *Codec[T]) ReadFrom(reader ByteRuneReader) (T, error) {
+	c.decoder.Reset(reader)
+	return c.Read()
+}
+
+// Read read the value from reader continuously, it must be called after calling Codec.ReadFrom
+func (c *Codec[T]) Read() (T, error) {
+	var v T
+	err := c.decoder.DecodeInto(&v)
+	return v, err
+}
+
+// ToObject convert bytes to the value
+func (c *Codec[T]) ToObject(bts []byte) (T, error) {
+	return c.ReadFrom(bufio.NewReader(bytes.NewReader(bts)))
+}
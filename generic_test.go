@@ -0,0 +1,97 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package hessian
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type genericItemT struct {
+	Name string
+}
+
+func (genericItemT) HessianCodecName() string {
+	return "test.Item"
+}
+
+type genericOrderT struct {
+	ID    int64
+	Item  genericItemT
+	Items []*genericItemT
+}
+
+func TestMarshalGeneric(t *testing.T) {
+	item := &genericItemT{Name: "pen"}
+	v := genericOrderT{ID: 1, Item: genericItemT{Name: "book"}, Items: []*genericItemT{item, item}}
+
+	bs, err := Marshal(v)
+	assert.Nil(t, err)
+
+	decoded, err := UnmarshalAs[genericOrderT](bs)
+	assert.Nil(t, err)
+	assert.Equal(t, v, decoded)
+	assert.True(t, decoded.Items[0] == decoded.Items[1])
+
+	pDecoded, err := UnmarshalAs[*genericOrderT](bs)
+	assert.Nil(t, err)
+	assert.Equal(t, &v, pDecoded)
+
+	// the class name of CodecNamable
+	bs, err = Marshal(genericItemT{Name: "a"})
+	assert.Nil(t, err)
+	o, err := ToObject(bs, nil, WithDynamicObject())
+	assert.Nil(t, err)
+	assert.Equal(t, "test.Item", o.(*Object).ClassName)
+
+	bs, err = Marshal([]genericItemT{{Name: "a"}, {Name: "b"}})
+	assert.Nil(t, err)
+	items, err := UnmarshalAs[[]genericItemT](bs)
+	assert.Nil(t, err)
+	assert.Equal(t, []genericItemT{{Name: "a"}, {Name: "b"}}, items)
+
+	_, err = UnmarshalAs[int32](bs)
+	assert.NotNil(t, err)
+}
+
+func TestCodec(t *testing.T) {
+	c := NewCodec[*genericOrderT]()
+	v := &genericOrderT{ID: 1, Item: genericItemT{Name: "book"}, Items: []*genericItemT{{Name: "pen"}}}
+
+	bs, err := c.ToBytes(v)
+	assert.Nil(t, err)
+	decoded, err := c.ToObject(bs)
+	assert.Nil(t, err)
+	assert.Equal(t, v, decoded)
+
+	buf := bytes.NewBuffer(nil)
+	assert.Nil(t, c.WriteTo(buf, v))
+	assert.Nil(t, c.Write(&genericOrderT{ID: 2, Items: []*genericItemT{}}))
+	assert.Nil(t, c.Write(nil))
+
+	decoded, err = c.ReadFrom(bufio.NewReader(buf))
+	assert.Nil(t, err)
+	assert.Equal(t, v, decoded)
+	decoded, err = c.Read()
+	assert.Nil(t, err)
+	assert.Equal(t, &genericOrderT{ID: 2, Items: []*genericItemT{}}, decoded)
+	decoded, err = c.Read()
+	assert.Nil(t, err)
+	assert.Nil(t, decoded)
+}
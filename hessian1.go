@@ -0,0 +1,703 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+// see: http://hessian.caucho.com/doc/hessian-1.0-spec.xtp
+//
+// Hessian 1.0 Grammar
+//
+// value  ::= 'N'                                      # null
+//        ::= 'T' | 'F'                                # boolean
+//        ::= 'I' b32 b24 b16 b8                       # 32-bit int
+//        ::= 'L' b64 b56 b48 b40 b32 b24 b16 b8       # 64-bit long
+//        ::= 'D' b64 b56 b48 b40 b32 b24 b16 b8       # 64-bit IEEE double
+//        ::= 'd' b64 b56 b48 b40 b32 b24 b16 b8       # date in milliseconds
+//        ::= ('s' b16 b8 utf8-data)* 'S' b16 b8 utf8-data  # string
+//        ::= ('x' b16 b8 utf8-data)* 'X' b16 b8 utf8-data  # xml
+//        ::= ('b' b16 b8 bytes)* 'B' b16 b8 bytes     # binary
+//        ::= 'V' type? length? value* 'z'             # list
+//        ::= 'M' type? (value value)* 'z'             # map
+//        ::= 'R' b32 b24 b16 b8                       # ref
+//
+// type   ::= 't' b16 b8 type-string
+// length ::= 'l' b32 b24 b16 b8
+//
+// call   ::= 'c' x01 x00 header* 'm' b16 b8 method-string value* 'z'
+// reply  ::= 'r' x01 x00 header* value 'z'
+//        ::= 'r' x01 x00 header* 'f' (string value)* 'z'
+// header ::= 'H' b16 b8 header-string value
+//
+// Hessian 1.0 has no class definition, an object is written as a typed map whose keys are the field names.
+// The length of a string is the number of characters, and the length of a type or method is the number of bytes.
+//
+// -------------- Examples
+//
+// ----------> int 300
+//
+// I x00 x00 x01 x2c
+//
+// ----------> int[] = {0, 1}
+//
+// V t x00 x04 [int    # typed list
+//   l x00 x00 x00 x02 # length = 2
+//   I x00 x00 x00 x00 # 0
+//   I x00 x00 x00 x01 # 1
+//   z
+//
+// ----------> class Car { String model = "Beetle"; }
+//
+// M t x00 x03 Car     # typed map
+//   S x00 x05 model
+//   S x00 x06 Beetle
+//   z
+//
+// The decoder detects the version from the leading bytes of the stream
+// when the reader supports Peek (e.g. bufio.Reader), and the version of a call or a reply is always detected.
+// Otherwise WithProtocolVersion(ProtocolVersion1) is required.
+
+package hessian
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"reflect"
+	"time"
+	"unicode/utf16"
+	"unsafe"
+)
+
+const (
+	_string1Chunk      = byte('s')
+	_xml1FinalChunk    = byte('X')
+	_xml1Chunk         = byte('x')
+	_binary1Chunk      = byte('b')
+	_date1Tag          = byte('d')
+	_list1Tag          = byte('V')
+	_ref1Tag           = byte('R')
+	_type1Tag          = byte('t')
+	_length1Tag        = byte('l')
+	_end1Flag          = byte('z')
+	_call1Tag          = byte('c')
+	_reply1Tag         = byte('r')
+	_method1Tag        = byte('m')
+	_header1Tag        = byte('H')
+	_fault1Tag         = byte('f')
+	_version1Major     = byte(0x01)
+	_version1Minor     = byte(0x00)
+	_string1ChunkSize  = _stringChunkSize
+	_binary1ChunkSize  = _binaryChunkSize
+	_lenString1MaxSize = 0xffff
+)
+
+var _interfaceSliceType = reflect.TypeOf([]interface{}{})
+
+func (e *Encoder) isHessian1() bool {
+	return e.version == ProtocolVersion1
+}
+
+// end flag of list and map
+func (e *Encoder) endFlag() byte {
+	if e.isHessian1() {
+		return _end1Flag
+	}
+	return _endFlag
+}
+
+func encodeInt1(value int32) []byte {
+	return []byte{
+		_int4ByteStartTag,
+		byte(value >> 24),
+		byte(value >> 16),
+		byte(value >> 8),
+		byte(value)}
+}
+
+func encodeLong1(value int64) []byte {
+	bs := make([]byte, 9)
+	bs[0] = _longStartTag
+	binary.BigEndian.PutUint64(bs[1:], uint64(value))
+	return bs
+}
+
+func encodeDouble1(value float64) []byte {
+	bs := make([]byte, 9)
+	bs[0] = _doubleLongStartTag
+	binary.BigEndian.PutUint64(bs[1:], math.Float64bits(value))
+	return bs
+}
+
+func encodeDate1(date time.Time) []byte {
+	if date.IsZero() {
+		return []byte{_nilTag}
+	}
+	bs := make([]byte, 9)
+	bs[0] = _date1Tag
+	binary.BigEndian.PutUint64(bs[1:], uint64(unixMillis(date)))
+	return bs
+}
+
+func encodeRef1(index int) []byte {
+	bs := make([]byte, 5)
+	bs[0] = _ref1Tag
+	binary.BigEndian.PutUint32(bs[1:], uint32(index))
+	return bs
+}
+
+func encodeString1(value string) []byte {
+	units := utf16Units(value)
+	length := len(units)
+	byteBuf := bytes.NewBuffer(nil)
+
+	begin := 0
+	for length > _string1ChunkSize {
+		sublen := stringChunkLen(units, begin, _string1ChunkSize)
+		byteBuf.Write([]byte{_string1Chunk, byte(sublen >> 8), byte(sublen)})
+		writeUTF16(byteBuf, units[begin:begin+sublen])
+
+		length -= sublen
+		begin += sublen
+	}
+
+	byteBuf.WriteByte(_stringFinalChunk)
+	byteBuf.WriteByte(byte(length >> 8))
+	byteBuf.WriteByte(byte(length))
+	writeUTF16(byteBuf, units[begin:])
+	return byteBuf.Bytes()
+}
+
+func encodeBinary1(value []byte) []byte {
+	length := len(value)
+	byteBuf := bytes.NewBuffer(nil)
+
+	begin := 0
+	for length > _binary1ChunkSize {
+		byteBuf.WriteByte(_binary1Chunk)
+		byteBuf.Write(_binaryChunkSizeBytes)
+		byteBuf.Write(value[begin : begin+_binary1ChunkSize])
+
+		length -= _binary1ChunkSize
+		begin += _binary1ChunkSize
+	}
+
+	byteBuf.WriteByte(_binaryFinalChunk)
+	byteBuf.WriteByte(byte(length >> 8))
+	byteBuf.WriteByte(byte(length))
+	byteBuf.Write(value[begin:])
+	return byteBuf.Bytes()
+}
+
+// write the tag and a string with the byte length, used by type, method and header
+func (e *Encoder) writeLenString1(tag byte, value string) (int, error) {
+	if len(value) > _lenString1MaxSize {
+		return 0, newCodecError("writeLenString1", "string too long: %d", len(value))
+	}
+	e.writeBT(tag, byte(len(value)>>8), byte(len(value)))
+	return e.writer.Write([]byte(value))
+}
+
+// write type of list or map, an empty type is written as java does
+func (e *Encoder) writeType1(typ string) (int, error) {
+	return e.writeLenString1(_type1Tag, typ)
+}
+
+func (e *Encoder) writeList1(vv reflect.Value, listTypeName string) (int, error) {
+	e.writeBT(_list1Tag)
+	if _, err := e.writeType1(listTypeName); err != nil {
+		return 0, err
+	}
+	e.writeBT(_length1Tag)
+	e.writeBytes(encodeInt1(int32(vv.Len()))[1:])
+
+	for i := 0; i < vv.Len(); i++ {
+		if _, err := e.WriteData(vv.Index(i).Interface()); err != nil {
+			return 0, err
+		}
+	}
+	e.writeBT(_end1Flag)
+	return vv.Len(), nil
+}
+
+// write object as a typed map
+func (e *Encoder) writeObject1(vv reflect.Value, clsName string) (int, error) {
+	e.writeBT(_mapTypedTag)
+	if _, err := e.writeType1(clsName); err != nil {
+		return 0, err
+	}
+
+	fields, err := e.structFields(vv.Type())
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for i := range fields {
+		fv := fieldByIndex(vv, fields[i].index, false)
+		if fields[i].omitEmpty && isEmptyValue(fv) {
+			continue
+		}
+		e.writeString(fields[i].name)
+		if err := e.writeFieldValue(fv, &fields[i]); err != nil {
+			return 0, err
+		}
+		count++
+	}
+	e.writeBT(_end1Flag)
+	return count, nil
+}
+
+func (d *Decoder) isHessian1() bool {
+	if d.protocolVersion == ProtocolVersionAuto {
+		d.protocolVersion = detectVersion(d.reader)
+	}
+	return d.protocolVersion == ProtocolVersion1
+}
+
+// detectVersion detect the protocol version by peeking the leading bytes,
+// hessian 2.0 is assumed if the reader can't peek or the leading bytes are the same in both versions.
+func detectVersion(reader ByteRuneReader) int {
+	peeker, ok := reader.(interface {
+		Peek(n int) ([]byte, error)
+	})
+	if !ok {
+		return ProtocolVersion2
+	}
+
+	bs, _ := peeker.Peek(3)
+	if len(bs) == 0 {
+		return ProtocolVersion2
+	}
+
+	switch bs[0] {
+	case _date1Tag:
+		// object ref 0x64 can't be the first value of hessian 2.0
+		return ProtocolVersion1
+	case _call1Tag, _reply1Tag:
+		if len(bs) == 3 && bs[1] == _version1Major && bs[2] == _version1Minor {
+			return ProtocolVersion1
+		}
+	case _mapTypedTag, _list1Tag:
+		// neither 't' nor 'l' is a valid type of hessian 2.0
+		if len(bs) > 1 && (bs[1] == _type1Tag || bs[1] == _length1Tag) {
+			return ProtocolVersion1
+		}
+	}
+	return ProtocolVersion2
+}
+
+func binary1Tag(tag byte) bool {
+	return tag == _binaryFinalChunk || tag == _binary1Chunk
+}
+
+func string1Tag(tag byte) bool {
+	return tag == _stringFinalChunk || tag == _string1Chunk || tag == _xml1FinalChunk || tag == _xml1Chunk
+}
+
+func string1EndTag(tag byte) bool {
+	return tag == _stringFinalChunk || tag == _xml1FinalChunk
+}
+
+func decodeString1Value(reader ByteRuneReader, flag int32) (string, error) {
+	tag, err := getTag(reader, flag)
+	if err != nil {
+		return "", err
+	}
+
+	if tag == _nilTag {
+		return "", nil
+	}
+
+	var units []uint16
+	for {
+		if !string1Tag(tag) {
+			return "", newCodecError("decodeString1Value", "error string tag: 0x%x", tag)
+		}
+
+		buf, err := readBytes(reader, 2)
+		if err != nil {
+			return "", err
+		}
+		if units, err = readUTF16(reader, int(binary.BigEndian.Uint16(buf)), units); err != nil {
+			return "", err
+		}
+
+		if string1EndTag(tag) {
+			return string(utf16.Decode(units)), nil
+		}
+
+		if tag, err = readTag(reader); err != nil {
+			return "", err
+		}
+	}
+}
+
+func decodeDate1Value(reader ByteRuneReader, flag int32) (time.Time, error) {
+	tag, err := getTag(reader, flag)
+	if err != nil {
+		return _zeroDate, err
+	}
+	if tag != _date1Tag {
+		return _zeroDate, newCodecError("decodeDate1Value", "error date tag: 0x%x", tag)
+	}
+
+	buf, err := readBytes(reader, 8)
+	if err != nil {
+		return _zeroDate, err
+	}
+	u64 := binary.BigEndian.Uint64(buf)
+	i64 := *(*int64)(unsafe.Pointer(&u64))
+	return millisTime(i64), nil
+}
+
+// read a string with the byte length, used by type, method and header
+func (d *Decoder) readLenString1() (string, error) {
+	buf, err := d.readBytes(2)
+	if err != nil {
+		return "", err
+	}
+	bs, err := d.readBytes(int(binary.BigEndian.Uint16(buf)))
+	if err != nil {
+		return "", err
+	}
+	return string(bs), nil
+}
+
+// readType1 read the optional type of list or map, and return the type and the next tag
+func (d *Decoder) readType1() (string, byte, error) {
+	tag, err := d.readTag()
+	if err != nil || tag != _type1Tag {
+		return "", tag, err
+	}
+
+	typ, err := d.readLenString1()
+	if err != nil {
+		return "", 0, err
+	}
+	tag, err = d.readTag()
+	return typ, tag, err
+}
+
+func (d *Decoder) readData1(flag int32) (interface{}, error) {
+	tag, err := getTag(d.reader, flag)
+	if err != nil {
+		hlog.Debugf("reading tag err:%v", err)
+		return nil, nil //ignore
+	}
+
+	switch {
+	case tag == _end1Flag:
+		return nil, io.EOF
+	case tag == _nilTag:
+		return nil, nil
+	case tag == _boolTrueTag:
+		return true, nil
+	case tag == _boolFalseTag:
+		return false, nil
+	case tag == _int4ByteStartTag:
+		return d.readInt(int32(tag))
+	case tag == _longStartTag:
+		return d.readLong(int32(tag))
+	case tag == _doubleLongStartTag:
+		return d.readDouble(int32(tag))
+	case tag == _date1Tag:
+		return d.readDate(int32(tag))
+	case string1Tag(tag):
+		return d.readString(int32(tag))
+	case binary1Tag(tag):
+		return d.readBinary(int32(tag))
+	case tag == _list1Tag:
+		return d.readList1()
+	case tag == _mapTypedTag:
+		return d.readMap1()
+	case tag == _ref1Tag:
+		return d.readRef(tag)
+	default:
+		return nil, newCodecError("readData1", "unknown tag: 0x%x", tag)
+	}
+}
+
+func (d *Decoder) readStruct1() (interface{}, error) {
+	tag, err := d.readTag()
+	if err != nil {
+		hlog.Debugf("reading tag err:%v", err)
+		return nil, nil //ignore
+	}
+
+	switch tag {
+	case _end1Flag:
+		return nil, io.EOF
+	case _nilTag:
+		return nil, nil
+	case _date1Tag:
+		return d.readDate(int32(tag))
+	case _mapTypedTag:
+		return d.readMap1()
+	case _ref1Tag:
+		return d.readRef(tag)
+	default:
+		return nil, newCodecError("readStruct1", "unknown tag: 0x%x", tag)
+	}
+}
+
+func (d *Decoder) readList1Value(flag int32) (interface{}, error) {
+	tag, err := getTag(d.reader, flag)
+	if err != nil {
+		hlog.Debugf("reading tag err:%v", err)
+		return nil, nil //ignore
+	}
+
+	switch {
+	case tag == _nilTag:
+		return nil, nil
+	case tag == _ref1Tag:
+		return d.readRef(tag)
+	case tag == _list1Tag:
+		return d.readList1()
+	case binary1Tag(tag):
+		return d.readBinary(int32(tag))
+	default:
+		return nil, newCodecError("readList1Value", "error list tag: 0x%x", tag)
+	}
+}
+
+// readList1 read list after the tag 'V',
+// it's decoded as []interface{} if the type is not registered.
+func (d *Decoder) readList1() (interface{}, error) {
+	expected, isList := expectKind(d.takeExpectType(), reflect.Slice, reflect.Array)
+	listTyp, tag, err := d.readType1()
+	if err != nil {
+		return nil, newCodecError("readList1", "read list type", err)
+	}
+
+	length := 0
+	if tag == _length1Tag {
+		n, err := d.readInt(int32(_int4ByteStartTag))
+		if err != nil {
+			return nil, newCodecError("readList1", "read list length", err)
+		}
+		if n > 0 {
+			length = int(n)
+		}
+		if tag, err = d.readTag(); err != nil {
+			return nil, newCodecError("readList1", err)
+		}
+	}
+
+	aryType, ok := d.findType(listTyp)
+	if isList {
+		// the static type of the destination takes precedence
+		aryType = reflect.SliceOf(expected.Elem())
+	} else if !ok || aryType.Kind() != reflect.Slice {
+		aryType = _interfaceSliceType
+	}
+
+	aryValue := reflect.MakeSlice(aryType, 0, length)
+	holder := d.addDecoderRef(aryValue)
+
+	for tag != _end1Flag {
+		item, err := d.readDataAs(aryType.Elem(), int32(tag))
+		if err != nil {
+			return nil, newCodecError("readList1", err)
+		}
+
+		aryValue = reflect.Append(aryValue, reflect.Zero(aryType.Elem()))
+		holder.change(aryValue)
+		elem := aryValue.Index(aryValue.Len() - 1)
+		if aryType.Elem().Kind() == reflect.Interface {
+			if it, _ := EnsureInterface(item, nil); it != nil {
+				elem.Set(reflect.ValueOf(it))
+			}
+		} else if item != nil {
+			v, err := d.convertElem(aryType.Elem(), item)
+			if err != nil {
+				return nil, newCodecError("readList1", err)
+			}
+			SetValue(elem, v)
+		}
+
+		if tag, err = d.readTag(); err != nil {
+			return nil, newCodecError("readList1", err)
+		}
+	}
+
+	return holder, nil
+}
+
+// readMap1 read map after the tag 'M', it's decoded as an object if the type is a registered struct or java enum,
+// or as map[interface{}]interface{} if the type is not registered.
+func (d *Decoder) readMap1() (interface{}, error) {
+	expected, isExpected := expectKind(d.takeExpectType(), reflect.Struct, reflect.Map)
+	typName, tag, err := d.readType1()
+	if err != nil {
+		return nil, newCodecError("readMap1", "read map type", err)
+	}
+
+	cls := ClassDef{FullClassName: typName}
+	if ok, v, err := d.readCustomObject(cls, func() (interface{}, error) { return d.readDynamicObject1(typName, tag) }); ok {
+		return v, err
+	}
+
+	mType, ok := d.findType(typName)
+	if isExpected {
+		// the static type of the destination takes precedence
+		mType, ok = expected, true
+	}
+	if ok && mType == _objectType {
+		return d.readDynamicObject1(typName, tag)
+	}
+	if ok && isJavaEnumType(mType) {
+		return EnsureInterface(d.readEnum1(mType, tag))
+	}
+
+	if ok && mType.Kind() == reflect.Struct {
+		return EnsureInterface(d.readObject1(mType, tag))
+	}
+
+	if !ok && typName != "" && d.dynamicObject {
+		return d.readDynamicObject1(typName, tag)
+	}
+
+	if ok && mType.Kind() == reflect.Map {
+		mPtrValue := PackPtr(reflect.MakeMap(mType))
+		d.addDecoderRef(mPtrValue)
+		if err = d.readMapEntries1(mPtrValue.Elem(), tag); err != nil {
+			return nil, err
+		}
+		return mPtrValue.Elem().Interface(), nil
+	}
+
+	m := make(map[interface{}]interface{})
+	d.addDecoderRef(reflect.ValueOf(&m))
+	for tag != _end1Flag {
+		key, err := EnsureInterface(d.readData1(int32(tag)))
+		if err != nil {
+			return nil, newCodecError("readMap1", err)
+		}
+		value, err := EnsureInterface(d.readData1(_tagRead))
+		if err != nil {
+			return nil, newCodecError("readMap1", err)
+		}
+		m[key] = value
+
+		if tag, err = d.readTag(); err != nil {
+			return nil, newCodecError("readMap1", err)
+		}
+	}
+	return m, nil
+}
+
+// read the entries of map until the end flag, tag is the first tag of the entries
+func (d *Decoder) readMapEntries1(mValue reflect.Value, tag byte) error {
+	for tag != _end1Flag {
+		key, err := d.readDataAs(mValue.Type().Key(), int32(tag))
+		if err != nil {
+			return newCodecError("readMapEntries1", err)
+		}
+		value, err := d.readDataAs(mValue.Type().Elem(), _tagRead)
+		if err != nil {
+			return newCodecError("readMapEntries1", err)
+		}
+		if err = d.setMapIndex(mValue, key, value); err != nil {
+			return newCodecError("readMapEntries1", err)
+		}
+
+		if tag, err = d.readTag(); err != nil {
+			return newCodecError("readMapEntries1", err)
+		}
+	}
+	return nil
+}
+
+// read the fields of object until the end flag, tag is the first tag of the fields
+func (d *Decoder) readObject1(typ reflect.Type, tag byte) (reflect.Value, error) {
+	if isBigNumberType(typ) {
+		return d.readBigNumber1(typ, tag)
+	}
+	if _, err := d.structFields(typ); err != nil {
+		return _zeroValue, newCodecError("readObject1", err)
+	}
+
+	vv := reflect.New(typ)
+	d.addDecoderRef(vv)
+
+	st := vv.Elem()
+	seen := make(map[string]int)
+	for tag != _end1Flag {
+		fldName, err := d.readString(int32(tag))
+		if err != nil {
+			return _zeroValue, newCodecError("readObject1", "read field name", err)
+		}
+
+		index, err := d.findField(fldName, typ, seen[fldName])
+		seen[fldName]++
+		if err != nil {
+			hlog.Debugf("%s is not found, will skip type ->p %v", fldName, typ)
+			if _, err = d.readData1(_tagRead); err != nil {
+				return _zeroValue, newCodecError("readObject1", "skip field '%s'", fldName, err)
+			}
+		} else if err = d.readStructField(fldName, st, index); err != nil {
+			return _zeroValue, newCodecError("readObject1", "failed to decode field '%s'", fldName, err)
+		}
+
+		if tag, err = d.readTag(); err != nil {
+			return _zeroValue, newCodecError("readObject1", err)
+		}
+	}
+	return vv, nil
+}
+
+// read map field into the dest
+func (d *Decoder) readMap1Value(dest reflect.Value) error {
+	tag, err := d.readTag()
+	if err != nil {
+		return newCodecError("readMap1Value", err)
+	}
+
+	switch tag {
+	case _nilTag:
+		return nil
+	case _ref1Tag:
+		r, err := d.readRef(tag)
+		if err != nil {
+			return err
+		}
+		SetValue(dest, r)
+		return nil
+	case _mapTypedTag:
+		// the type is ignored for the type of dest is known
+		_, tag, err = d.readType1()
+		if err != nil {
+			return newCodecError("readMap1Value", "read map type", err)
+		}
+	case _list1Tag:
+		if isSetType(UnpackPtrType(dest.Type())) {
+			list, err := d.readList1()
+			if err != nil {
+				return newCodecError("readMap1Value", err)
+			}
+			return d.readSet(dest, list)
+		}
+		return newCodecError("readMap1Value", "error map tag: 0x%x", tag)
+	default:
+		return newCodecError("readMap1Value", "error map tag: 0x%x", tag)
+	}
+
+	mPtrValue := PackPtr(reflect.MakeMap(UnpackPtrType(dest.Type())))
+	d.addDecoderRef(mPtrValue)
+	if err = d.readMapEntries1(mPtrValue.Elem(), tag); err != nil {
+		return err
+	}
+	SetValue(dest, mPtrValue)
+	return nil
+}
@@ -0,0 +1,225 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package hessian
+
+import (
+	"bufio"
+	"bytes"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func encodeHessian1(t *testing.T, nameMap map[string]string, v interface{}) []byte {
+	bs, err := ToBytes(v, nameMap, WithProtocolVersion(ProtocolVersion1))
+	assert.Nil(t, err)
+	return bs
+}
+
+func TestHessian1Encode(t *testing.T) {
+	assert.Equal(t, []byte{'I', 0x00, 0x00, 0x01, 0x2c}, encodeHessian1(t, nil, int32(300)))
+	assert.Equal(t, []byte{'L', 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x2c}, encodeHessian1(t, nil, int64(300)))
+	assert.Equal(t, []byte{'D', 0x40, 0x28, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00}, encodeHessian1(t, nil, 12.25))
+	assert.Equal(t, []byte{'S', 0x00, 0x05, 'h', 'e', 'l', 'l', 'o'}, encodeHessian1(t, nil, "hello"))
+	assert.Equal(t, []byte{'B', 0x00, 0x02, 0x01, 0x02}, encodeHessian1(t, nil, []byte{1, 2}))
+	assert.Equal(t, []byte{'d', 0x00, 0x00, 0x00, 0xd0, 0x4b, 0x92, 0x84, 0xb8},
+		encodeHessian1(t, nil, time.Unix(894621091, 0)))
+
+	assert.Equal(t, []byte{
+		'V', 't', 0x00, 0x04, '[', 'i', 'n', 't',
+		'l', 0x00, 0x00, 0x00, 0x02,
+		'I', 0x00, 0x00, 0x00, 0x00,
+		'I', 0x00, 0x00, 0x00, 0x01,
+		'z',
+	}, encodeHessian1(t, map[string]string{"[]int32": "[int"}, []int32{0, 1}))
+
+	type car struct {
+		Model string
+	}
+	assert.Equal(t, []byte{
+		'M', 't', 0x00, 0x03, 'C', 'a', 'r',
+		'S', 0x00, 0x05, 'm', 'o', 'd', 'e', 'l',
+		'S', 0x00, 0x06, 'B', 'e', 'e', 't', 'l', 'e',
+		'z',
+	}, encodeHessian1(t, map[string]string{"car": "Car"}, car{Model: "Beetle"}))
+}
+
+func TestHessian1RoundTrip(t *testing.T) {
+	typMap, nameMap := ExtractTypeNameMap(P{})
+	now := time.Unix(time.Now().Unix(), 0)
+
+	for _, v := range []interface{}{
+		true, false, int32(-300), int64(1) << 40, 1.5, "hello", []byte{1, 2, 3}, now,
+		&P{X: 1, Y: 2, Z: 3, Name: "p"},
+		[]interface{}{int32(1), "a", nil},
+		map[interface{}]interface{}{"a": int32(1)},
+	} {
+		bs := encodeHessian1(t, nameMap, v)
+
+		// auto detected or specified version
+		for _, opts := range [][]Option{nil, {WithProtocolVersion(ProtocolVersion1)}} {
+			decoded, err := ToObject(bs, typMap, opts...)
+			assert.Nil(t, err)
+			assert.Equal(t, v, decoded)
+		}
+	}
+
+	// the leading chunk 's' is the same as a hessian 2.0 typed list, so the version must be specified
+	long := string(bytes.Repeat([]byte("中"), _stringChunkSize+10))
+	decoded, err := ToObject(encodeHessian1(t, nil, long), nil, WithProtocolVersion(ProtocolVersion1))
+	assert.Nil(t, err)
+	assert.Equal(t, long, decoded)
+}
+
+func TestHessian1Ref(t *testing.T) {
+	c := buildSingleCircularObject()
+	typMap, nameMap := ExtractTypeNameMap(c)
+	bs := encodeHessian1(t, nameMap, c)
+
+	decoded, err := ToObject(bs, typMap)
+	assert.Nil(t, err)
+	d, ok := decoded.(*circularT)
+	if assert.True(t, ok) {
+		assert.Equal(t, c.Num, d.Num)
+		assert.True(t, d == d.Previous && d == d.Next)
+	}
+}
+
+func TestHessian1Fields(t *testing.T) {
+	type fieldsT struct {
+		Names  []string
+		Scores map[string]int32
+		Inner  *P
+	}
+	v := &fieldsT{
+		Names:  []string{"a", "b"},
+		Scores: map[string]int32{"a": 1},
+		Inner:  &P{X: 1, Name: "inner"},
+	}
+	typMap, nameMap := ExtractTypeNameMap(v)
+	decoded, err := ToObject(encodeHessian1(t, nameMap, v), typMap)
+	assert.Nil(t, err)
+	assert.Equal(t, v, decoded)
+}
+
+func TestHessian1Call(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	e := NewEncoder(buf, nil, WithProtocolVersion(ProtocolVersion1))
+	assert.Nil(t, e.WriteCall("add2", int32(2), int32(3)))
+	assert.Equal(t, []byte{
+		'c', 0x01, 0x00,
+		'm', 0x00, 0x04, 'a', 'd', 'd', '2',
+		'I', 0x00, 0x00, 0x00, 0x02,
+		'I', 0x00, 0x00, 0x00, 0x03,
+		'z',
+	}, buf.Bytes())
+
+	// the version of call is detected even if the reader can't peek
+	call, err := NewDecoder(bytes.NewReader(buf.Bytes()), nil).ReadCall()
+	assert.Nil(t, err)
+	assert.Equal(t, &Call{Method: "add2", Args: []interface{}{int32(2), int32(3)}}, call)
+
+	// headers are skipped
+	call, err = NewDecoder(bufio.NewReader(bytes.NewReader([]byte{
+		'c', 0x01, 0x00,
+		'H', 0x00, 0x02, 'i', 'd', 'S', 0x00, 0x01, '1',
+		'm', 0x00, 0x04, 'p', 'i', 'n', 'g',
+		'z',
+	})), nil).ReadCall()
+	assert.Nil(t, err)
+	assert.Equal(t, &Call{Method: "ping", Args: []interface{}{}}, call)
+}
+
+func TestHessian1Reply(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	e := NewEncoder(buf, nil, WithProtocolVersion(ProtocolVersion1))
+	assert.Nil(t, e.WriteReply(int32(5)))
+	assert.Equal(t, []byte{'r', 0x01, 0x00, 'I', 0x00, 0x00, 0x00, 0x05, 'z'}, buf.Bytes())
+
+	value, err := NewDecoder(bufio.NewReader(buf), nil).ReadReply()
+	assert.Nil(t, err)
+	assert.Equal(t, int32(5), value)
+
+	buf.Reset()
+	e.Reset(buf)
+	assert.Nil(t, e.WriteFault(FaultServiceException, "File Not Found", nil))
+	_, err = NewDecoder(bufio.NewReader(buf), nil).ReadReply()
+	assert.Equal(t, &Fault{Code: FaultServiceException, Message: "File Not Found"}, err)
+}
+
+func TestHessian1Truncated(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	e := NewEncoder(buf, nil, WithProtocolVersion(ProtocolVersion1))
+	assert.Nil(t, e.WriteCall("add2", int32(2), int32(3)))
+	call := buf.Bytes()
+
+	buf = bytes.NewBuffer(nil)
+	e.Reset(buf)
+	assert.Nil(t, e.WriteFault(FaultServiceException, "File Not Found", nil))
+	fault := buf.Bytes()
+
+	// cut before the end tag and inside the last value
+	for _, n := range []int{1, 3} {
+		_, err := NewDecoder(bufio.NewReader(bytes.NewReader(call[:len(call)-n])), nil).ReadCall()
+		assert.NotNil(t, err)
+
+		_, err = NewDecoder(bufio.NewReader(bytes.NewReader(fault[:len(fault)-n])), nil).ReadReply()
+		_, isFault := err.(*Fault)
+		assert.True(t, err != nil && !isFault, "%v", err)
+	}
+}
+
+func TestHessian1ClientServer(t *testing.T) {
+	typMap, nameMap := ExtractTypeNameMap(P{})
+	server := httptest.NewServer(NewServer(pointServiceT{}, typMap, nameMap))
+	defer server.Close()
+	client := NewClient(server.URL, typMap, nameMap, WithCodecOptions(WithProtocolVersion(ProtocolVersion1)))
+
+	moved, err := client.Invoke("move", P{X: 1, Name: "p"}, int64(2))
+	assert.Nil(t, err)
+	assert.Equal(t, &P{X: 3, Name: "p"}, moved)
+
+	_, err = client.Invoke("divide", int32(6), int32(0))
+	fault, ok := err.(*Fault)
+	if assert.True(t, ok, "expect fault but get %v", err) {
+		assert.Equal(t, FaultServiceException, fault.Code)
+	}
+}
+
+func TestDetectVersion(t *testing.T) {
+	for _, c := range []struct {
+		leading []byte
+		version int
+	}{
+		{[]byte{'c', 0x01, 0x00}, ProtocolVersion1},
+		{[]byte{'r', 0x01, 0x00}, ProtocolVersion1},
+		{[]byte{'M', 't', 0x00}, ProtocolVersion1},
+		{[]byte{'V', 'l', 0x00}, ProtocolVersion1},
+		{[]byte{'d', 0x00, 0x00}, ProtocolVersion1},
+		{[]byte{'H', 0x02, 0x00}, ProtocolVersion2},
+		{[]byte{'M', 0x04, 'C'}, ProtocolVersion2},
+		{[]byte{'S', 0x00, 0x01}, ProtocolVersion2},
+		{[]byte{'N'}, ProtocolVersion2},
+		{nil, ProtocolVersion2},
+	} {
+		assert.Equal(t, c.version, detectVersion(bufio.NewReader(bytes.NewReader(c.leading))), "leading %v", c.leading)
+	}
+
+	// can't peek
+	assert.Equal(t, ProtocolVersion2, detectVersion(bytes.NewReader([]byte{'c', 0x01, 0x00})))
+}
@@ -120,6 +120,17 @@ func untypedListTag(tag byte) bool {
 
 // write as fixed-length list
 func (e *Encoder) writeList(data interface{}) (int, error) {
+	return e.writeListType(data, "")
+}
+
+// write the list with the type name, the type name is looked up in the name map if it's empty
+func (e *Encoder) writeListType(data interface{}, listTypeName string) (int, error) {
+	// the nil slice is written as null, and the empty slice as an empty list
+	if v := UnpackPtrValue(reflect.ValueOf(data)); v.Kind() == reflect.Slice && v.IsNil() {
+		e.writeBT(_nilTag)
+		return 1, nil
+	}
+
 	if bt, ok := data.([]byte); ok {
 		return e.writeBinary(bt)
 	}
@@ -135,11 +146,27 @@ func (e *Encoder) writeList(data interface{}) (int, error) {
 	// unpack to parser values
 	vv = UnpackPtrValue(vv)
 
-	typ := UnpackPtrType(vv.Type())
-	arrayTypeName := TypeName(typ)
-	listTypeName, ok := e.nameMap[arrayTypeName]
+	if listTypeName == "" {
+		typ := UnpackPtrType(vv.Type())
+		arrayTypeName := TypeName(typ)
+		var ok bool
+		listTypeName, ok = e.nameMap[arrayTypeName]
+
+		if !ok || _interfaceTypeName == arrayRootElemName(arrayTypeName) {
+			listTypeName = e.javaListClass
+		}
+	}
 
-	if !ok || _interfaceTypeName == arrayRootElemName(arrayTypeName) {
+	return e.writeTypedList(vv, listTypeName)
+}
+
+// write the list with the type name, the list is untyped if the type name is empty
+func (e *Encoder) writeTypedList(vv reflect.Value, listTypeName string) (int, error) {
+	if e.isHessian1() {
+		return e.writeList1(vv, listTypeName)
+	}
+
+	if listTypeName == "" {
 		// fixed-length untyped list
 		e.writeBT(_listFixedUntypedTag)
 		e.writeInt(int32(vv.Len()))
@@ -162,6 +189,10 @@ func (e *Encoder) writeList(data interface{}) (int, error) {
 
 //ReadList read list
 func (d *Decoder) ReadList(flag int32) (interface{}, error) {
+	if d.isHessian1() {
+		return d.readList1Value(flag)
+	}
+
 	tag, err := getTag(d.reader, flag)
 	if err != nil {
 		hlog.Debugf("reading tag err:%v", err)
@@ -192,6 +223,7 @@ func (d *Decoder) ReadList(flag int32) (interface{}, error) {
 //      ::= 'V' type int value*   # fixed-length list
 //      ::= [x70-77] type value*  # fixed-length typed list
 func (d *Decoder) readTypedList(tag byte) (interface{}, error) {
+	expected, isList := expectKind(d.takeExpectType(), reflect.Slice, reflect.Array)
 	listTyp, err := d.readType()
 	if err != nil {
 		return nil, newCodecError("readTypedList", "read list type: %s", listTyp, err)
@@ -220,7 +252,11 @@ func (d *Decoder) readTypedList(tag byte) (interface{}, error) {
 		return nil, nil
 	}
 
-	aryType, ok := d.typMap[listTyp]
+	aryType, ok := d.findType(listTyp)
+	if isList {
+		// the static type of the destination takes precedence
+		aryType, ok = reflect.SliceOf(expected.Elem()), true
+	}
 	if !ok {
 		return nil, newCodecError("readTypedList", "can't find list type %s", listTyp)
 	}
@@ -229,23 +265,28 @@ func (d *Decoder) readTypedList(tag byte) (interface{}, error) {
 	holder := d.addDecoderRef(aryValue)
 
 	for j := 0; j < length || isVariableArr; j++ {
-		item, err := d.ReadData()
+		// read the tag first to tell the end of the stream from the null element
+		tag, err := d.readTag()
 		if err != nil {
-			if err == io.EOF && isVariableArr {
-				break
-			}
 			return nil, newCodecError("readTypedList", err)
 		}
-
-		if item == nil {
+		if tag == _endFlag && isVariableArr {
 			break
 		}
+		item, err := d.readDataAs(aryType.Elem(), int32(tag))
+		if err != nil {
+			return nil, newCodecError("readTypedList", err)
+		}
 
-		v := EnsureRawValue(item)
 		if isVariableArr {
-			aryValue = reflect.Append(aryValue, v)
+			aryValue = reflect.Append(aryValue, reflect.Zero(aryType.Elem()))
 			holder.change(aryValue)
-		} else {
+		}
+		if item != nil {
+			v, err := d.convertElem(aryType.Elem(), item)
+			if err != nil {
+				return nil, newCodecError("readTypedList", err)
+			}
 			SetValue(aryValue.Index(j), v)
 		}
 	}
@@ -259,6 +300,7 @@ func (d *Decoder) readTypedList(tag byte) (interface{}, error) {
 //      ::= x58 int value*        # fixed-length untyped list
 //      ::= [x78-7f] value*       # fixed-length untyped list
 func (d *Decoder) readUntypedList(tag byte) (interface{}, error) {
+	elemType := expectElem(d.takeExpectType())
 	isVariableArr := tag == _listVariableUntypedTag
 
 	length := -1
@@ -288,19 +330,27 @@ func (d *Decoder) readUntypedList(tag byte) (interface{}, error) {
 	holder := d.addDecoderRef(aryValue)
 
 	for j := 0; j < length || isVariableArr; j++ {
-		it, err := d.ReadData()
+		it, err := d.readDataAs(elemType, _tagRead)
 		if err != nil {
 			if err == io.EOF && isVariableArr {
 				continue
 			}
 			return nil, newCodecError("readUntypedList", err)
 		}
+		if it != nil && elemType != nil && isNumericKind(UnpackPtrType(elemType).Kind()) {
+			// the number is converted to the element type of the destination by the numeric policy
+			v, err := d.convertElem(elemType, it)
+			if err != nil {
+				return nil, newCodecError("readUntypedList", err)
+			}
+			it = v.Interface()
+		}
 
 		if isVariableArr {
 			aryValue = reflect.Append(aryValue, EnsureRawValue(it))
 			holder.change(aryValue)
 		} else {
-			ary[j] = it
+			ary[j], _ = EnsureInterface(it, nil)
 		}
 	}
 
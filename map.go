@@ -85,31 +85,43 @@ const (
 )
 
 func (e *Encoder) writeMap(data interface{}) (int, error) {
+	return e.writeMapType(data, "")
+}
+
+// write the map with the type name, the type name is looked up in the name map if it's empty
+func (e *Encoder) writeMapType(data interface{}, mapName string) (int, error) {
 	// object data MUST not be unpacked
 	vv := reflect.ValueOf(data)
 
+	// check nil map before ref, the empty map is written as an empty map
+	if uv := UnpackPtrValue(vv); (uv.Kind() == reflect.Ptr && !uv.Elem().IsValid()) || (uv.Kind() == reflect.Map && uv.IsNil()) {
+		e.writeBT(_nilTag)
+		return 1, nil
+	}
+
 	// check ref
 	if n, ok := e.checkEncodeRefMap(vv); ok {
 		return e.writeRef(n)
 	}
 
 	vv = UnpackPtrValue(vv)
-	// check nil map
-	if vv.Kind() == reflect.Ptr && !vv.Elem().IsValid() {
-		e.writeBT(_nilTag)
-		return 0, nil
-	}
-
-	keys := vv.MapKeys()
-	if len(keys) == 0 {
-		e.writeBT(_nilTag)
-		return 0, nil
-	}
 
 	typ := vv.Type()
+	if typ.Kind() == reflect.Map && isSetType(typ) && typ.Elem().Kind() == reflect.Struct {
+		return e.writeSet(vv, mapName)
+	}
 
-	mapName, ok := e.nameMap[typ.Name()]
-	if ok {
+	ok := mapName != ""
+	if !ok {
+		mapName, ok = e.nameMap[typ.Name()]
+	}
+	if !ok && e.javaMapClass != "" {
+		mapName, ok = e.javaMapClass, true
+	}
+	if e.isHessian1() {
+		e.writeBT(_mapTypedTag)
+		e.writeType1(mapName)
+	} else if ok {
 		e.writeBT(_mapTypedTag)
 		e.writeString(mapName)
 	} else {
@@ -146,18 +158,25 @@ func (e *Encoder) writeMap(data interface{}) (int, error) {
 		}
 	}
 
-	e.writeBT(_endFlag)
+	e.writeBT(e.endFlag())
 
 	return count, nil
 }
 
 //readTypedMap read typed map
 func (d *Decoder) readTypedMap() (interface{}, error) {
+	expected, isExpected := expectKind(d.takeExpectType(), reflect.Struct, reflect.Map)
 	typ, err := d.readType()
 	if err != nil {
 		return nil, newCodecError("ReadType", err)
 	}
-	mType, ok := d.typMap[typ]
+	if ok, v, err := d.readCustomObject(ClassDef{FullClassName: typ}, d.readUntypedMap); ok {
+		return v, err
+	}
+	mType, ok := d.findType(typ)
+	if isExpected {
+		mType, ok = expected, true
+	}
 	if !ok {
 		return nil, newCodecError("ReadType", "no type map for %v", typ)
 	}
@@ -166,6 +185,9 @@ func (d *Decoder) readTypedMap() (interface{}, error) {
 	if mType.Kind() == reflect.Map {
 		mValue = reflect.MakeMap(mType)
 	} else {
+		if _, err = d.structFields(mType); err != nil {
+			return nil, newCodecError("ReadType", err)
+		}
 		mValue = reflect.New(mType)
 	}
 
@@ -173,8 +195,13 @@ func (d *Decoder) readTypedMap() (interface{}, error) {
 	mValue = mPtrValue.Elem()
 	d.addDecoderRef(mPtrValue)
 
+	var keyType, valueType reflect.Type
+	if mType.Kind() == reflect.Map {
+		keyType, valueType = mType.Key(), mType.Elem()
+	}
+
 	for {
-		key, err := d.ReadData()
+		key, err := d.readDataAs(keyType, _tagRead)
 		if err != nil {
 			if err == io.EOF {
 				// EOF error means already read the end flag of map
@@ -188,20 +215,21 @@ func (d *Decoder) readTypedMap() (interface{}, error) {
 			break
 		}
 
-		value, err := d.ReadData()
+		value, err := d.readDataAs(valueType, _tagRead)
 		if err != nil {
 			return nil, err
 		}
 		if mType.Kind() == reflect.Map {
-			mValue.SetMapIndex(EnsureRawValue(key), EnsureRawValue(value))
+			if err = d.setMapIndex(mValue, key, value); err != nil {
+				return nil, newCodecError("readTypedMap", err)
+			}
 		} else {
 			fieldName, ok := key.(string)
 			if !ok {
 				return nil, newCodecError("readTypedMap", "the type of map key must be string, but get [%v]", key)
 			}
-			fieldValue := mValue.FieldByName(fieldName)
-			if fieldValue.IsValid() {
-				fieldValue.Set(EnsureRawValue(value))
+			if index, err := d.findField(fieldName, mType, 0); err == nil && value != nil {
+				SetValue(fieldByIndex(mValue.Elem(), index, true), EnsureRawValue(value))
 			}
 		}
 	}
@@ -212,12 +240,17 @@ func (d *Decoder) readTypedMap() (interface{}, error) {
 
 //readUntypedMap read untyped map
 func (d *Decoder) readUntypedMap() (interface{}, error) {
+	var keyType, valueType reflect.Type
+	if expected, ok := expectKind(d.takeExpectType(), reflect.Map); ok {
+		keyType, valueType = expected.Key(), expected.Elem()
+	}
+
 	m := make(map[interface{}]interface{})
 	d.addDecoderRef(reflect.ValueOf(&m))
 
 	//read key and value
 	for {
-		key, err := EnsureInterface(d.ReadData())
+		key, err := EnsureInterface(d.readDataAs(keyType, _tagRead))
 		if err != nil {
 			if err == io.EOF {
 				// EOF error means already read the end flag of map
@@ -231,7 +264,7 @@ func (d *Decoder) readUntypedMap() (interface{}, error) {
 			break
 		}
 
-		value, err := EnsureInterface(d.ReadData())
+		value, err := EnsureInterface(d.readDataAs(valueType, _tagRead))
 		if err != nil {
 			return nil, err
 		}
@@ -242,6 +275,10 @@ func (d *Decoder) readUntypedMap() (interface{}, error) {
 }
 
 func (d *Decoder) readMap(dest reflect.Value) error {
+	if d.isHessian1() {
+		return d.readMap1Value(dest)
+	}
+
 	tag, _ := d.readTag()
 
 	switch tag {
@@ -256,10 +293,20 @@ func (d *Decoder) readMap(dest reflect.Value) error {
 		SetValue(dest, r)
 		return nil
 	case _mapTypedTag:
-		d.readString(_tagRead)
+		// the type is ignored for the type of dest is known
+		if _, err := d.readType(); err != nil {
+			return newCodecError("readMap", err)
+		}
 	case _mapUntypedTag:
 		//do nothing
 	default:
+		if (typedListTag(tag) || untypedListTag(tag)) && isSetType(UnpackPtrType(dest.Type())) {
+			list, err := d.ReadList(int32(tag))
+			if err != nil {
+				return newCodecError("readMap", err)
+			}
+			return d.readSet(dest, list)
+		}
 		return newCodecError("readMap", "error map tag: 0x%x", tag)
 	}
 
@@ -269,7 +316,7 @@ func (d *Decoder) readMap(dest reflect.Value) error {
 
 	//read key and value
 	for {
-		key, err := d.ReadData()
+		key, err := d.readDataAs(mapTyp.Key(), _tagRead)
 		if err != nil {
 			if err == io.EOF {
 				// EOF error means already read the end flag of map
@@ -283,11 +330,13 @@ func (d *Decoder) readMap(dest reflect.Value) error {
 			break
 		}
 
-		vl, err := d.ReadData()
+		vl, err := d.readDataAs(mapTyp.Elem(), _tagRead)
 		if err != nil {
 			return err
 		}
-		mPtrValue.Elem().SetMapIndex(EnsureRawValue(key), EnsureRawValue(vl))
+		if err = d.setMapIndex(mPtrValue.Elem(), key, vl); err != nil {
+			return newCodecError("readMap", err)
+		}
 	}
 	SetValue(dest, mPtrValue)
 	return nil
@@ -0,0 +1,106 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package hessian
+
+import (
+	"reflect"
+)
+
+// HessianMarshaler is the interface implemented by the types which encode themselves into hessian,
+// the value is written by the encoder, e.g. WriteData, and the lists, maps and objects written by it
+// take part in the ref and class definition tracking of the encoder.
+//
+//	type Money struct {
+//	  Amount   int64
+//	  Currency string
+//	}
+//
+//	func (m Money) MarshalHessian(e *hessian.Encoder) error {
+//	  _, err := e.WriteData(fmt.Sprintf("%d %s", m.Amount, m.Currency))
+//	  return err
+//	}
+type HessianMarshaler interface {
+	MarshalHessian(e *Encoder) error
+}
+
+// HessianUnmarshaler is the interface implemented by the types which decode themselves from hessian,
+// the value written by the HessianMarshaler is read by the decoder, e.g. ReadObject, including the null value.
+type HessianUnmarshaler interface {
+	UnmarshalHessian(d *Decoder) error
+}
+
+var _marshalerType = reflect.TypeOf((*HessianMarshaler)(nil)).Elem()
+
+var _unmarshalerType = reflect.TypeOf((*HessianUnmarshaler)(nil)).Elem()
+
+// writeMarshaler write the value by its HessianMarshaler, the nil pointer is written as null
+func (e *Encoder) writeMarshaler(m HessianMarshaler) (int, error) {
+	if v := reflect.ValueOf(m); v.Kind() == reflect.Ptr && v.IsNil() {
+		return e.writeBT(_nilTag)
+	}
+	if err := m.MarshalHessian(e); err != nil {
+		return 0, newCodecError("MarshalHessian", "marshal %T", m, err)
+	}
+	return 1, nil
+}
+
+// readUnmarshaler read the dest by the HessianUnmarshaler implemented by its type or pointer type,
+// the nil pointer is allocated, it returns false if the HessianUnmarshaler is not implemented.
+func (d *Decoder) readUnmarshaler(dest reflect.Value) (bool, error) {
+	var u HessianUnmarshaler
+	switch {
+	case dest.Kind() == reflect.Ptr && dest.Type().Implements(_unmarshalerType):
+		if dest.IsNil() {
+			dest.Set(reflect.New(dest.Type().Elem()))
+		}
+		u = dest.Interface().(HessianUnmarshaler)
+	case dest.CanAddr() && reflect.PtrTo(dest.Type()).Implements(_unmarshalerType):
+		u = dest.Addr().Interface().(HessianUnmarshaler)
+	default:
+		return false, nil
+	}
+
+	if err := u.UnmarshalHessian(d); err != nil {
+		return true, newCodecError("UnmarshalHessian", "unmarshal %v", dest.Type(), err)
+	}
+	return true, nil
+}
+
+// readUnmarshalerAs read the value of the expected type, e.g. the element of a list or the value of a map,
+// by the HessianUnmarshaler implemented by its pointer type, and return the pointer to the value.
+// It returns false if the HessianUnmarshaler is not implemented.
+// The flag is the tag already read of hessian 1.0, which is unread for the unmarshaler to read the whole value.
+func (d *Decoder) readUnmarshalerAs(typ reflect.Type, flag int32) (bool, interface{}, error) {
+	if typ == nil || typ.Kind() == reflect.Interface {
+		return false, nil, nil
+	}
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if !reflect.PtrTo(typ).Implements(_unmarshalerType) {
+		return false, nil, nil
+	}
+
+	if flag != _tagRead {
+		if err := d.unreadTag(); err != nil {
+			return true, nil, newCodecError("UnmarshalHessian", "unread the tag 0x%x of %v", flag, typ, err)
+		}
+	}
+
+	vp := reflect.New(typ)
+	_, err := d.readUnmarshaler(vp.Elem())
+	return true, vp, err
+}
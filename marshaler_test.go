@@ -0,0 +1,154 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package hessian
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type moneyT struct {
+	Amount   int64
+	Currency string
+}
+
+func (m moneyT) MarshalHessian(e *Encoder) error {
+	_, err := e.WriteData(fmt.Sprintf("%d %s", m.Amount, m.Currency))
+	return err
+}
+
+func (m *moneyT) UnmarshalHessian(d *Decoder) error {
+	s, err := d.ReadObject()
+	if err != nil || s == nil {
+		return err
+	}
+	_, err = fmt.Sscanf(s.(string), "%d %s", &m.Amount, &m.Currency)
+	return err
+}
+
+// a legacy wrapper written as a list of its items
+type itemsT struct {
+	items []*itemT
+}
+
+type itemT struct {
+	Name string
+}
+
+func (w *itemsT) MarshalHessian(e *Encoder) error {
+	_, err := e.WriteData(w.items)
+	return err
+}
+
+func (w *itemsT) UnmarshalHessian(d *Decoder) error {
+	list, err := d.ReadObject()
+	if err != nil {
+		return err
+	}
+	for _, item := range list.([]interface{}) {
+		w.items = append(w.items, item.(*itemT))
+	}
+	return nil
+}
+
+type orderT struct {
+	Price    moneyT
+	Discount *moneyT
+	Items    itemsT
+	Gifts    *itemsT
+}
+
+type failMarshalerT struct{}
+
+func (failMarshalerT) MarshalHessian(*Encoder) error {
+	return errors.New("fail")
+}
+
+func TestHessianMarshaler(t *testing.T) {
+	item := &itemT{Name: "book"}
+	v := &orderT{
+		Price: moneyT{Amount: 100, Currency: "USD"},
+		Items: itemsT{items: []*itemT{item, item}},
+		Gifts: &itemsT{items: []*itemT{item}},
+	}
+	nameMap := map[string]string{"orderT": "test.Order", "itemT": "test.Item"}
+	typMap := map[string]reflect.Type{"test.Order": reflect.TypeOf(orderT{}), "test.Item": reflect.TypeOf(itemT{})}
+
+	for _, version := range []int{ProtocolVersion2, ProtocolVersion1} {
+		bs, err := ToBytes(v, nameMap, WithProtocolVersion(version))
+		assert.Nil(t, err)
+
+		decoded, err := ToObject(bs, typMap, WithProtocolVersion(version))
+		assert.Nil(t, err)
+		order := decoded.(*orderT)
+		assert.Equal(t, v.Price, order.Price)
+		assert.Equal(t, &moneyT{}, order.Discount)
+		assert.Equal(t, v.Items, order.Items)
+		assert.Equal(t, v.Gifts, order.Gifts)
+
+		// the items written by the marshalers share the refs
+		assert.True(t, order.Items.items[0] == order.Items.items[1])
+		assert.True(t, order.Items.items[0] == order.Gifts.items[0])
+	}
+
+	bs, err := ToBytes(moneyT{Amount: 5, Currency: "CNY"}, nil)
+	assert.Nil(t, err)
+	s, err := ToObject(bs, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "5 CNY", s)
+
+	bs, err = ToBytes((*moneyT)(nil), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{_nilTag}, bs)
+
+	_, err = ToBytes(failMarshalerT{}, nil)
+	assert.NotNil(t, err)
+}
+
+type walletT struct {
+	Coins    []moneyT
+	Notes    []*moneyT
+	Accounts map[string]moneyT
+}
+
+func TestHessianUnmarshalerElements(t *testing.T) {
+	v := &walletT{
+		Coins:    []moneyT{{Amount: 1, Currency: "USD"}, {Amount: 2, Currency: "CNY"}},
+		Notes:    []*moneyT{{Amount: 100, Currency: "EUR"}},
+		Accounts: map[string]moneyT{"saving": {Amount: 5, Currency: "JPY"}},
+	}
+	nameMap := map[string]string{"walletT": "test.Wallet"}
+	typMap := map[string]reflect.Type{"test.Wallet": reflect.TypeOf(walletT{})}
+
+	for _, version := range []int{ProtocolVersion2, ProtocolVersion1} {
+		bs, err := ToBytes(v, nameMap, WithProtocolVersion(version))
+		assert.Nil(t, err)
+		decoded, err := ToObject(bs, typMap, WithProtocolVersion(version))
+		assert.Nil(t, err)
+		assert.Equal(t, v, decoded)
+
+		// the slice of the marshaler type
+		bs, err = ToBytes(v.Coins, nil, WithProtocolVersion(version))
+		assert.Nil(t, err)
+		var coins []moneyT
+		assert.Nil(t, Unmarshal(bs, &coins, WithProtocolVersion(version)))
+		assert.Equal(t, v.Coins, coins)
+	}
+}
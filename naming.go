@@ -0,0 +1,74 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package hessian
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// NamingStrategy the strategy to convert the go field names to the java field names,
+// which is used for the fields without tag names.
+type NamingStrategy struct {
+	fieldName func(goName string) string
+
+	// the cached fields of struct types by _structFieldsKey
+	fields sync.Map
+}
+
+// the naming strategies
+var (
+	// LowerCamelNaming the go field name with the first letter in lower case, e.g. UserName -> userName, the default strategy
+	LowerCamelNaming = NewNamingStrategy(func(goName string) string {
+		name, _ := lowerName(goName)
+		return name
+	})
+
+	// ExactNaming the same as the go field name, e.g. UserName -> UserName
+	ExactNaming = NewNamingStrategy(func(goName string) string {
+		return goName
+	})
+
+	// SnakeCaseNaming the go field name in snake case, e.g. UserName -> user_name, HTTPServer -> http_server
+	SnakeCaseNaming = NewNamingStrategy(snakeCase)
+)
+
+// NewNamingStrategy create a naming strategy with the function converting the go field name to the java field name.
+// The field names of struct types are cached by the strategy, so a strategy should be created once and reused.
+func NewNamingStrategy(fieldName func(goName string) string) *NamingStrategy {
+	return &NamingStrategy{fieldName: fieldName}
+}
+
+// FieldName return the java field name of the go field name
+func (s *NamingStrategy) FieldName(goName string) string {
+	return s.fieldName(goName)
+}
+
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (!unicode.IsUpper(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) && runes[i-1] != '_' {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
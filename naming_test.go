@@ -0,0 +1,107 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package hessian
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type namingT struct {
+	UserName string
+	HTTPCode int32
+	Nick     string `hessian:"nick_name"`
+}
+
+func TestSnakeCase(t *testing.T) {
+	for name, expected := range map[string]string{
+		"Name":       "name",
+		"UserName":   "user_name",
+		"HTTPServer": "http_server",
+		"UserID":     "user_id",
+		"Addr2Line":  "addr2_line",
+		"User_Name":  "user_name",
+	} {
+		assert.Equal(t, expected, snakeCase(name), name)
+	}
+}
+
+func TestNamingStrategy(t *testing.T) {
+	v := &namingT{UserName: "tom", HTTPCode: 200, Nick: "t"}
+	nameMap := map[string]string{"namingT": "test.Naming"}
+	typMap := map[string]reflect.Type{"test.Naming": reflect.TypeOf(namingT{})}
+	upper := NewNamingStrategy(strings.ToUpper)
+
+	for _, c := range []struct {
+		naming *NamingStrategy
+		fields map[string]interface{}
+	}{
+		{LowerCamelNaming, map[string]interface{}{"userName": "tom", "hTTPCode": int32(200), "nick_name": "t"}},
+		{ExactNaming, map[string]interface{}{"UserName": "tom", "HTTPCode": int32(200), "nick_name": "t"}},
+		{SnakeCaseNaming, map[string]interface{}{"user_name": "tom", "http_code": int32(200), "nick_name": "t"}},
+		{upper, map[string]interface{}{"USERNAME": "tom", "HTTPCODE": int32(200), "nick_name": "t"}},
+	} {
+		bs, err := ToBytes(v, nameMap, WithNamingStrategy(c.naming))
+		assert.Nil(t, err)
+		assert.Equal(t, c.fields, dynamicFields(t, bs))
+
+		decoded, err := ToObject(bs, typMap, WithNamingStrategy(c.naming))
+		assert.Nil(t, err)
+		assert.Equal(t, v, decoded)
+
+		// the fields are cached by the strategy
+		_, ok := c.naming.fields.Load(_structFieldsKey{reflect.TypeOf(namingT{}), false})
+		assert.True(t, ok)
+	}
+}
+
+func TestCaseInsensitive(t *testing.T) {
+	v := &namingT{UserName: "tom", HTTPCode: 200, Nick: "t"}
+	bs, err := ToBytes(v, map[string]string{"namingT": "test.Naming"}, WithNamingStrategy(NewNamingStrategy(strings.ToUpper)))
+	assert.Nil(t, err)
+
+	typMap := map[string]reflect.Type{"test.Naming": reflect.TypeOf(namingT{})}
+	decoded, err := ToObject(bs, typMap)
+	assert.Nil(t, err)
+	assert.Equal(t, &namingT{Nick: "t"}, decoded)
+
+	decoded, err = ToObject(bs, typMap, WithCaseInsensitive())
+	assert.Nil(t, err)
+	assert.Equal(t, v, decoded)
+}
+
+func TestTypedMapToStruct(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	e := NewEncoder(buf, nil)
+	e.writeBT(_mapTypedTag)
+	e.writeString("test.Naming")
+	e.writeString("username")
+	e.writeString("tom")
+	e.writeString("httpcode")
+	e.writeInt(200)
+	e.writeString("unknown")
+	e.writeString("x")
+	e.writeBT(_endFlag)
+
+	typMap := map[string]reflect.Type{"test.Naming": reflect.TypeOf(namingT{})}
+	decoded, err := ToObject(buf.Bytes(), typMap, WithCaseInsensitive())
+	assert.Nil(t, err)
+	assert.Equal(t, &namingT{UserName: "tom", HTTPCode: 200}, decoded)
+}
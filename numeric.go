@@ -0,0 +1,216 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package hessian
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+)
+
+// NumericPolicy the policy to convert the int, long and double values to the numeric fields of different types
+type NumericPolicy int
+
+const (
+	// NumericStrict convert the values of any numeric form, e.g. a long to an int field,
+	// and return ErrNumberOverflow or ErrPrecisionLoss if the value can't be converted exactly, the default policy.
+	NumericStrict NumericPolicy = iota
+
+	// NumericLenient convert the values as the go conversion, the overflow and precision loss are ignored.
+	NumericLenient
+)
+
+// UintOverflowPolicy the policy to encode the unsigned values larger than math.MaxInt64, which can't be written as long
+type UintOverflowPolicy int
+
+const (
+	// UintOverflowError return ErrNumberOverflow for the unsigned values larger than math.MaxInt64, the default policy.
+	UintOverflowError UintOverflowPolicy = iota
+
+	// UintOverflowBigInteger write the unsigned values larger than math.MaxInt64 as java.math.BigInteger.
+	UintOverflowBigInteger
+)
+
+// the errors of the numeric conversion
+var (
+	ErrNumberOverflow = errors.New("number overflow")
+	ErrPrecisionLoss  = errors.New("number precision loss")
+)
+
+const (
+	// the bounds of the float64 values which can be converted to int64 and uint64
+	_int64Bound  = float64(1 << 63)
+	_uint64Bound = float64(1 << 64)
+)
+
+// readNumberField read the numeric field, the value of any numeric form is converted to the type of the field,
+// and the null value leaves the field unchanged.
+func (d *Decoder) readNumberField(fldName string, dest reflect.Value) error {
+	data, err := EnsureInterface(d.ReadData())
+	if err != nil || data == nil {
+		return err
+	}
+
+	v := reflect.New(UnpackPtrType(dest.Type())).Elem()
+	if err = d.convertNumber(data, v); err != nil {
+		return newCodecError("readNumberField", "field %s", fldName, err)
+	}
+	SetValue(dest, v)
+	return nil
+}
+
+// convertNumber convert the int32, int64 or float64 value to the numeric value by the numeric policy
+func (o *options) convertNumber(data interface{}, dest reflect.Value) error {
+	strict := o.numericPolicy == NumericStrict
+	switch dest.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		switch n := data.(type) {
+		case int32:
+			i = int64(n)
+		case int64:
+			i = n
+		case float64:
+			if strict && (math.IsNaN(n) || n < -_int64Bound || n >= _int64Bound) {
+				return newCodecError("convertNumber", "%v to %v", n, dest.Type(), ErrNumberOverflow)
+			}
+			if strict && n != math.Trunc(n) {
+				return newCodecError("convertNumber", "%v to %v", n, dest.Type(), ErrPrecisionLoss)
+			}
+			i = int64(n)
+		case *big.Int:
+			if strict && !n.IsInt64() {
+				return newCodecError("convertNumber", "%v to %v", n, dest.Type(), ErrNumberOverflow)
+			}
+			i = n.Int64()
+		default:
+			return newCodecError("convertNumber", "can't convert %T to %v", data, dest.Type())
+		}
+		if strict && dest.OverflowInt(i) {
+			return newCodecError("convertNumber", "%v to %v", i, dest.Type(), ErrNumberOverflow)
+		}
+		dest.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		switch n := data.(type) {
+		case int32, int64:
+			i := EnsureInt64(n)
+			if strict && i < 0 {
+				return newCodecError("convertNumber", "%v to %v", i, dest.Type(), ErrNumberOverflow)
+			}
+			u = uint64(i)
+		case float64:
+			if strict && (math.IsNaN(n) || n < 0 || n >= _uint64Bound) {
+				return newCodecError("convertNumber", "%v to %v", n, dest.Type(), ErrNumberOverflow)
+			}
+			if strict && n != math.Trunc(n) {
+				return newCodecError("convertNumber", "%v to %v", n, dest.Type(), ErrPrecisionLoss)
+			}
+			u = uint64(n)
+		case *big.Int:
+			if strict && !n.IsUint64() {
+				return newCodecError("convertNumber", "%v to %v", n, dest.Type(), ErrNumberOverflow)
+			}
+			u = n.Uint64()
+		default:
+			return newCodecError("convertNumber", "can't convert %T to %v", data, dest.Type())
+		}
+		if strict && dest.OverflowUint(u) {
+			return newCodecError("convertNumber", "%v to %v", u, dest.Type(), ErrNumberOverflow)
+		}
+		dest.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		switch n := data.(type) {
+		case int32:
+			f = float64(n)
+		case int64:
+			f = float64(n)
+			if strict && (f >= _int64Bound || int64(f) != n) {
+				return newCodecError("convertNumber", "%v to %v", n, dest.Type(), ErrPrecisionLoss)
+			}
+		case float64:
+			f = n
+		case *big.Int:
+			var acc big.Accuracy
+			f, acc = new(big.Float).SetInt(n).Float64()
+			if strict && acc != big.Exact {
+				return newCodecError("convertNumber", "%v to %v", n, dest.Type(), ErrPrecisionLoss)
+			}
+		default:
+			return newCodecError("convertNumber", "can't convert %T to %v", data, dest.Type())
+		}
+		// the double is rounded to the nearest float32, which is not a precision loss
+		if strict && dest.OverflowFloat(f) {
+			return newCodecError("convertNumber", "%v to %v", f, dest.Type(), ErrNumberOverflow)
+		}
+		dest.SetFloat(f)
+	default:
+		return newCodecError("convertNumber", "unsupported numeric type %v", dest.Type())
+	}
+	return nil
+}
+
+// convertElem convert the decoded list element, map key or value to the type as convertValue,
+// the number is converted to the numeric type by the numeric policy.
+func (o *options) convertElem(typ reflect.Type, in interface{}) (reflect.Value, error) {
+	elemType := UnpackPtrType(typ)
+	switch data, _ := EnsureInterface(in, nil); data.(type) {
+	case int32, int64, float64, *big.Int:
+		if isNumericKind(elemType.Kind()) {
+			v := reflect.New(elemType).Elem()
+			if err := o.convertNumber(data, v); err != nil {
+				return v, err
+			}
+			dest := reflect.New(typ).Elem()
+			SetValue(dest, v)
+			return dest, nil
+		}
+	}
+	return convertValue(typ, in), nil
+}
+
+func isNumericKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// writeInteger write the int value as int if it's in the range of int32, otherwise as long
+func (e *Encoder) writeInteger(i int64) (int, error) {
+	if i >= math.MinInt32 && i <= math.MaxInt32 {
+		return e.writeInt(int32(i))
+	}
+	return e.writeLong(i)
+}
+
+// writeUnsigned write the unsigned value as long,
+// and the value larger than math.MaxInt64 is written by the uint overflow policy.
+func (e *Encoder) writeUnsigned(source interface{}, u uint64) (int, error) {
+	if u <= math.MaxInt64 {
+		return e.writeLong(int64(u))
+	}
+	if e.uintOverflowPolicy == UintOverflowBigInteger {
+		return e.writeBigInteger(source, new(big.Int).SetUint64(u))
+	}
+	return 0, newCodecError("writeUnsigned", "%v", u, ErrNumberOverflow)
+}
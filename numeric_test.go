@@ -0,0 +1,219 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package hessian
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// the java class with the numeric fields of long, int and double
+type numberSourceT struct {
+	A int64
+	B int32
+	C float64
+	D int64
+}
+
+func (numberSourceT) HessianCodecName() string {
+	return "test.Number"
+}
+
+type numberTargetT struct {
+	A int32
+	B int64
+	C int16
+	D *float64
+}
+
+type numberUintT struct {
+	A uint8
+	B uint64
+	C uint32
+	D float32
+}
+
+func decodeNumber(t *testing.T, source numberSourceT, target interface{}, version int, opts ...Option) (interface{}, error) {
+	bs, err := ToBytes(source, map[string]string{"numberSourceT": "test.Number"}, WithProtocolVersion(version))
+	assert.Nil(t, err)
+	opts = append(opts, WithProtocolVersion(version))
+	return ToObject(bs, map[string]reflect.Type{"test.Number": reflect.TypeOf(target)}, opts...)
+}
+
+func TestNumericConversion(t *testing.T) {
+	for _, version := range []int{ProtocolVersion2, ProtocolVersion1} {
+		d := 3.0
+		decoded, err := decodeNumber(t, numberSourceT{A: 100000, B: -2, C: 3, D: 3}, numberTargetT{}, version)
+		assert.Nil(t, err)
+		assert.Equal(t, &numberTargetT{A: 100000, B: -2, C: 3, D: &d}, decoded)
+
+		decoded, err = decodeNumber(t, numberSourceT{A: 255, B: math.MaxInt32, C: 4096, D: 1 << 24}, numberUintT{}, version)
+		assert.Nil(t, err)
+		assert.Equal(t, &numberUintT{A: 255, B: math.MaxInt32, C: 4096, D: 1 << 24}, decoded)
+
+		for _, c := range []struct {
+			source numberSourceT
+			target interface{}
+			err    error
+		}{
+			{numberSourceT{A: math.MaxInt32 + 1}, numberTargetT{}, ErrNumberOverflow},
+			{numberSourceT{C: 1.5}, numberTargetT{}, ErrPrecisionLoss},
+			{numberSourceT{C: 40000}, numberTargetT{}, ErrNumberOverflow},
+			{numberSourceT{D: 1<<53 + 1}, numberTargetT{}, ErrPrecisionLoss},
+			{numberSourceT{A: 256}, numberUintT{}, ErrNumberOverflow},
+			{numberSourceT{B: -1}, numberUintT{}, ErrNumberOverflow},
+			{numberSourceT{C: -1}, numberUintT{}, ErrNumberOverflow},
+		} {
+			_, err = decodeNumber(t, c.source, c.target, version)
+			assert.True(t, errors.Is(err, c.err), "%v: %v", c.source, err)
+
+			_, err = decodeNumber(t, c.source, c.target, version, WithNumericPolicy(NumericLenient))
+			assert.Nil(t, err)
+		}
+
+		decoded, err = decodeNumber(t, numberSourceT{A: 256, B: -1, C: 1.5}, numberUintT{}, version, WithNumericPolicy(NumericLenient))
+		assert.Nil(t, err)
+		assert.Equal(t, &numberUintT{A: 0, B: math.MaxUint64, C: 1}, decoded)
+	}
+}
+
+func TestNumericNull(t *testing.T) {
+	type nullT struct {
+		A int32
+		B *int64
+	}
+	bs, err := ToBytes(&Object{ClassName: "test.Null", Fields: []string{"a", "b"}, Values: []interface{}{nil, nil}}, nil)
+	assert.Nil(t, err)
+	decoded, err := ToObject(bs, map[string]reflect.Type{"test.Null": reflect.TypeOf(nullT{})})
+	assert.Nil(t, err)
+	assert.Equal(t, &nullT{}, decoded)
+}
+
+type integerT struct {
+	A int
+	B int
+	C uint
+	D uint64
+}
+
+func TestIntegerEncoding(t *testing.T) {
+	nameMap := map[string]string{"integerT": "test.Integer"}
+	typMap := map[string]reflect.Type{"test.Integer": reflect.TypeOf(integerT{})}
+
+	for _, version := range []int{ProtocolVersion2, ProtocolVersion1} {
+		// the int out of the range of int32 is written as long
+		v := &integerT{A: 1, B: math.MaxInt32 + 1, C: 2, D: math.MaxInt64}
+		bs, err := ToBytes(v, nameMap, WithProtocolVersion(version))
+		assert.Nil(t, err)
+		o, err := ToObject(bs, nil, WithDynamicObject(), WithProtocolVersion(version))
+		assert.Nil(t, err)
+		assert.Equal(t, []interface{}{int32(1), int64(math.MaxInt32 + 1), int64(2), int64(math.MaxInt64)}, o.(*Object).Values)
+		decoded, err := ToObject(bs, typMap, WithProtocolVersion(version))
+		assert.Nil(t, err)
+		assert.Equal(t, v, decoded)
+
+		// the unsigned value out of the range of long
+		v = &integerT{D: math.MaxUint64}
+		_, err = ToBytes(v, nameMap, WithProtocolVersion(version))
+		assert.True(t, errors.Is(err, ErrNumberOverflow), "%v", err)
+
+		bs, err = ToBytes(v, nameMap, WithProtocolVersion(version), WithUintOverflowPolicy(UintOverflowBigInteger))
+		assert.Nil(t, err)
+		o, err = ToObject(bs, nil, WithDynamicObject(), WithProtocolVersion(version))
+		assert.Nil(t, err)
+		assert.Equal(t, new(big.Int).SetUint64(math.MaxUint64), o.(*Object).Values[3])
+		decoded, err = ToObject(bs, typMap, WithProtocolVersion(version))
+		assert.Nil(t, err)
+		assert.Equal(t, v, decoded)
+
+		// the big integer out of the range of the field
+		_, err = ToObject(bs, map[string]reflect.Type{"test.Integer": reflect.TypeOf(numberSourceT{})}, WithProtocolVersion(version))
+		assert.True(t, errors.Is(err, ErrNumberOverflow), "%v", err)
+	}
+}
+
+func TestNumericCollectionOverflow(t *testing.T) {
+	for _, version := range []int{ProtocolVersion2, ProtocolVersion1} {
+		for _, listOpts := range [][]Option{nil, {WithJavaListClass("java.util.LinkedList")}} {
+			bs, err := ToBytes([]int64{1, 1 << 40}, nil, append(listOpts, WithProtocolVersion(version))...)
+			assert.Nil(t, err)
+
+			var ints []int32
+			err = Unmarshal(bs, &ints, WithProtocolVersion(version))
+			assert.True(t, errors.Is(err, ErrNumberOverflow), "%v", err)
+			var ptrs []*int32
+			err = Unmarshal(bs, &ptrs, WithProtocolVersion(version))
+			assert.True(t, errors.Is(err, ErrNumberOverflow), "%v", err)
+
+			assert.Nil(t, Unmarshal(bs, &ints, WithProtocolVersion(version), WithNumericPolicy(NumericLenient)))
+			assert.Equal(t, []int32{1, 0}, ints)
+
+			bs, err = ToBytes([]int64{1, 2}, nil, append(listOpts, WithProtocolVersion(version))...)
+			assert.Nil(t, err)
+			assert.Nil(t, Unmarshal(bs, &ints, WithProtocolVersion(version)))
+			assert.Equal(t, []int32{1, 2}, ints)
+		}
+
+		bs, err := ToBytes(map[string]int64{"a": 1 << 40}, nil, WithProtocolVersion(version))
+		assert.Nil(t, err)
+		var m map[string]int32
+		err = Unmarshal(bs, &m, WithProtocolVersion(version))
+		assert.True(t, errors.Is(err, ErrNumberOverflow), "%v", err)
+
+		bs, err = ToBytes(map[int64]string{1 << 40: "a"}, nil, WithProtocolVersion(version))
+		assert.Nil(t, err)
+		var keys map[int32]string
+		err = Unmarshal(bs, &keys, WithProtocolVersion(version))
+		assert.True(t, errors.Is(err, ErrNumberOverflow), "%v", err)
+
+		bs, err = ToBytes(map[string]int64{"a": 1}, nil, WithProtocolVersion(version))
+		assert.Nil(t, err)
+		assert.Nil(t, Unmarshal(bs, &m, WithProtocolVersion(version)))
+		assert.Equal(t, map[string]int32{"a": 1}, m)
+
+		// the fields of the slice and map
+		type source struct {
+			Codes  []int64
+			Scores map[string]int64
+		}
+		type target struct {
+			Codes  []int32
+			Scores map[string]int32
+		}
+		nameMap := map[string]string{"source": "test.Codes"}
+		typMap := map[string]reflect.Type{"test.Codes": reflect.TypeOf(target{})}
+		bs, err = ToBytes(source{Codes: []int64{1 << 40}}, nameMap, WithProtocolVersion(version))
+		assert.Nil(t, err)
+		_, err = ToObject(bs, typMap, WithProtocolVersion(version))
+		assert.True(t, errors.Is(err, ErrNumberOverflow), "%v", err)
+
+		bs, err = ToBytes(source{Scores: map[string]int64{"a": 1 << 40}}, nameMap, WithProtocolVersion(version))
+		assert.Nil(t, err)
+		_, err = ToObject(bs, typMap, WithProtocolVersion(version))
+		assert.True(t, errors.Is(err, ErrNumberOverflow), "%v", err)
+
+		bs, err = ToBytes(source{Codes: []int64{1}, Scores: map[string]int64{"a": 2}}, nameMap, WithProtocolVersion(version))
+		assert.Nil(t, err)
+		decoded, err := ToObject(bs, typMap, WithProtocolVersion(version))
+		assert.Nil(t, err)
+		assert.Equal(t, &target{Codes: []int32{1}, Scores: map[string]int32{"a": 2}}, decoded)
+	}
+}
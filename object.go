@@ -124,6 +124,11 @@ func objectLenTag(tag byte) bool {
 
 //see: http://hessian.caucho.com/doc/hessian-serialization.html##object
 func (e *Encoder) writeObject(data interface{}) (int, error) {
+	// check date type for date is a struct, a date is a value without ref
+	if date, ok := UnpackPtrValue(reflect.ValueOf(data)).Interface().(time.Time); ok {
+		return e.writeDate(date)
+	}
+
 	// object data MUST not be unpacked
 	vv := reflect.ValueOf(data)
 
@@ -134,46 +139,41 @@ func (e *Encoder) writeObject(data interface{}) (int, error) {
 
 	vv = UnpackPtrValue(vv)
 
-	// check date type for date is a struct
-	if date, ok := vv.Interface().(time.Time); ok {
-		return e.writeBytes(encodeDate(date))
-	}
-
 	typ := vv.Type()
+	// the name map may be shared by the encoders, which must not be changed
 	clsName, ok := e.nameMap[typ.Name()]
 	if !ok {
 		clsName = typ.Name()
-		e.nameMap[clsName] = clsName
+	}
+	if e.isHessian1() {
+		return e.writeObject1(vv, clsName)
+	}
+	fields, err := e.structFields(typ)
+	if err != nil {
+		return 0, err
 	}
 	length, ok := e.existClassDef(clsName)
 	if !ok {
-		length, _ = e.writeClsDef(typ, clsName)
+		length, _ = e.writeClsDef(fields, clsName)
 	}
-	if byte(length) <= _objectTagMaxLen {
-		// NOTE: when length=2, length+_objectLenTagMin='b', the same as the binary chunk start with,
-		// which will be special processed in decoder
-		e.writeBT(byte(length) + _objectLenTagMin)
-	} else {
-		e.writeBT(_objectTag)
-		e.writeInt(int32(length))
-	}
-	for i := 0; i < vv.NumField(); i++ {
-		_, err := e.WriteData(vv.Field(i).Interface())
-		if err != nil {
+	e.writeObjectTag(length)
+	for i := range fields {
+		if err := e.writeFieldValue(fieldByIndex(vv, fields[i].index, false), &fields[i]); err != nil {
 			return 0, err
 		}
 	}
-	return vv.NumField(), nil
+	return len(fields), nil
+}
+
+func (e *Encoder) writeClsDef(fields []_structField, clsName string) (int, error) {
+	return e.writeClsDefFields(clsName, structFieldNames(fields))
 }
 
-func (e *Encoder) writeClsDef(typ reflect.Type, clsName string) (int, error) {
+func (e *Encoder) writeClsDefFields(clsName string, fldList []string) (int, error) {
 	e.writeBT(_objectDefTag)
 	e.writeString(clsName)
-	fldList := make([]string, typ.NumField())
 	e.writeInt(int32(len(fldList)))
 	for i := 0; i < len(fldList); i++ {
-		str, _ := lowerName(typ.Field(i).Name)
-		fldList[i] = str
 		e.writeString(fldList[i])
 	}
 	clsDef := ClassDef{clsName, fldList}
@@ -182,6 +182,49 @@ func (e *Encoder) writeClsDef(typ reflect.Type, clsName string) (int, error) {
 	return length, nil
 }
 
+// write an object of the class which is not a go struct, e.g. java enum,
+// the field values are written by writeField in the order of the field names.
+func (e *Encoder) writeClassObject(clsName string, fldList []string, writeField func(fld string) error) (int, error) {
+	if e.isHessian1() {
+		e.writeBT(_mapTypedTag)
+		if _, err := e.writeType1(clsName); err != nil {
+			return 0, err
+		}
+		for _, fld := range fldList {
+			e.writeString(fld)
+			if err := writeField(fld); err != nil {
+				return 0, err
+			}
+		}
+		e.writeBT(_end1Flag)
+		return len(fldList), nil
+	}
+
+	length, ok := e.existClassDef(clsName)
+	if !ok || !reflect.DeepEqual(e.clsDefList[length].FieldName, fldList) {
+		length, _ = e.writeClsDefFields(clsName, fldList)
+	}
+	e.writeObjectTag(length)
+	for _, fld := range fldList {
+		if err := writeField(fld); err != nil {
+			return 0, err
+		}
+	}
+	return len(fldList), nil
+}
+
+// write the tag of object instance which refers to the class def
+func (e *Encoder) writeObjectTag(length int) {
+	if length <= int(_objectTagMaxLen) {
+		// NOTE: when length=2, length+_objectLenTagMin='b', the same as the binary chunk start with,
+		// which will be special processed in decoder
+		e.writeBT(byte(length) + _objectLenTagMin)
+	} else {
+		e.writeBT(_objectTag)
+		e.writeInt(int32(length))
+	}
+}
+
 func (e *Encoder) existClassDef(clsName string) (int, bool) {
 	for i := 0; i < len(e.clsDefList); i++ {
 		if strings.Compare(clsName, e.clsDefList[i].FullClassName) == 0 {
@@ -217,12 +260,10 @@ func (d *Decoder) readClassDef() (interface{}, error) {
 func (d *Decoder) readTagObject() (interface{}, error) {
 	i, _ := d.readInt(_tagRead)
 	idx := int(i)
-	clsD := d.clsDefList[idx]
-	typ, ok := d.typMap[clsD.FullClassName]
-	if !ok {
-		return nil, newCodecError("readTagObject", "undefined type: %s", clsD.FullClassName)
+	if idx < 0 || idx >= len(d.clsDefList) {
+		return nil, newCodecError("readTagObject", "cls def ref index %d over max %d", idx, len(d.clsDefList))
 	}
-	return EnsureInterface(d.readObject(typ, clsD))
+	return d.readClassObject(d.clsDefList[idx], "readTagObject")
 }
 
 //ReadLenTagObject read length tag object
@@ -231,12 +272,31 @@ func (d *Decoder) ReadLenTagObject(tag byte) (interface{}, error) {
 	if i >= len(d.clsDefList) {
 		return nil, newCodecError("ReadLenTagObject", "cls def ref index %d over max %d", i, len(d.clsDefList))
 	}
-	clsD := d.clsDefList[i]
-	typ, ok := d.typMap[clsD.FullClassName]
+	return d.readClassObject(d.clsDefList[i], "ReadLenTagObject")
+}
+
+// readClassObject read the object of the class definition,
+// the expected struct type takes precedence over the type registered for the class.
+func (d *Decoder) readClassObject(cls ClassDef, caller string) (interface{}, error) {
+	expected, isStruct := expectKind(d.takeExpectType(), reflect.Struct)
+	if ok, v, err := d.readCustomObject(cls, func() (interface{}, error) { return d.readDynamicObject(cls) }); ok {
+		return v, err
+	}
+
+	typ, ok := d.findType(cls.FullClassName)
+	if isStruct {
+		typ, ok = expected, true
+	}
 	if !ok {
-		return nil, newCodecError("ReadLenTagObject", "undefined type: %s", clsD.FullClassName)
+		if d.dynamicObject {
+			return d.readDynamicObject(cls)
+		}
+		return nil, newCodecError(caller, "undefined type: %s", cls.FullClassName)
+	}
+	if typ == _objectType {
+		return d.readDynamicObject(cls)
 	}
-	return EnsureInterface(d.readObject(typ, clsD))
+	return EnsureInterface(d.readObject(typ, cls))
 }
 
 //readObjectDef read object def
@@ -268,9 +328,18 @@ func (d *Decoder) readObjectDef() (interface{}, error) {
 // var readObjectIndex = 0
 
 func (d *Decoder) readObject(typ reflect.Type, cls ClassDef) (interface{}, error) {
+	if isJavaEnumType(typ) {
+		return d.readEnum(typ, cls)
+	}
+	if isBigNumberType(typ) {
+		return d.readBigNumber(typ, cls)
+	}
 	if typ.Kind() != reflect.Struct {
 		return nil, newCodecError("readObject", "expect type struct but get %v", typ)
 	}
+	if _, err := d.structFields(typ); err != nil {
+		return nil, newCodecError("readObject", err)
+	}
 	vv := reflect.New(typ)
 	d.addDecoderRef(vv)
 
@@ -278,21 +347,21 @@ func (d *Decoder) readObject(typ reflect.Type, cls ClassDef) (interface{}, error
 	// readObjectIndexCurr := readObjectIndex
 
 	st := vv.Elem()
+	seen := make(map[string]int, len(cls.FieldName))
 	for i := 0; i < len(cls.FieldName); i++ {
 		fldName := cls.FieldName[i]
-		index, err := findField(fldName, typ)
+		index, err := d.findField(fldName, typ, seen[fldName])
+		seen[fldName]++
 
 		// fmt.Printf("[%d]  >>>> start read field %s: %v, %v, %p\n", readObjectIndexCurr, fldName, vv.Type(), vv.Interface(), vv.Interface())
 		if err != nil {
 			hlog.Debugf("%s is not found, will skip type ->p %v", fldName, typ)
+			if _, err = d.ReadData(); err != nil {
+				return nil, newCodecError("readObject", "skip field '%s'", fldName, err)
+			}
 			continue
 		}
-		fldValue := st.Field(index)
-		if !fldValue.CanSet() {
-			return nil, newCodecError("readObject", "field %s can set", fldName)
-		}
-
-		err = d.readField(fldName, fldValue)
+		err = d.readStructField(fldName, st, index)
 		if err != nil {
 			return nil, newCodecError("readObject", "failed to decode field '%s'", fldName, err)
 		}
@@ -303,66 +372,61 @@ func (d *Decoder) readObject(typ reflect.Type, cls ClassDef) (interface{}, error
 }
 
 func (d *Decoder) readField(fldName string, fldValue reflect.Value) error {
+	if ok, err := d.readUnmarshaler(fldValue); ok {
+		return err
+	}
+	if ok, err := d.readCustomField(fldValue); ok {
+		return err
+	}
 	sourceValue := fldValue
 	typ := UnpackPtrType(fldValue.Type())
 	fldValue = UnpackPtrValue(fldValue)
+	if isJavaEnumType(typ) {
+		return d.readEnumField(sourceValue)
+	}
 	switch typ.Kind() {
 	case reflect.String:
-		str, err := d.readString(_tagRead)
-		if err != nil {
+		data, err := EnsureInterface(d.ReadData())
+		if err != nil || data == nil {
+			// the null leaves the field unchanged
 			return err
 		}
-		if str != "" {
-			fldValue.SetString(str)
+		str, ok := data.(string)
+		if !ok {
+			return newCodecError("readField", "field %s: expect string but get %T", fldName, data)
 		}
-	case reflect.Int32, reflect.Int, reflect.Int16, reflect.Int8:
-		i, err := d.readInt(_tagRead)
-		if err != nil {
-			return err
-		}
-		v := int64(i)
-		fldValue.SetInt(v)
-	case reflect.Uint8, reflect.Uint16:
-		i, err := d.readInt(_tagRead)
-		if err != nil {
-			return err
-		}
-		v := uint64(i)
-		fldValue.SetUint(v)
-	case reflect.Int64:
-		i, err := d.readLong(_tagRead)
-		if err != nil {
-			return err
-		}
-		fldValue.SetInt(i)
-	case reflect.Uint64, reflect.Uint, reflect.Uint32:
-		i, err := d.readLong(_tagRead)
-		if err != nil {
-			return err
-		}
-		fldValue.SetUint(uint64(i))
+		v := reflect.New(typ).Elem()
+		v.SetString(str)
+		SetValue(sourceValue, v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return d.readNumberField(fldName, sourceValue)
 	case reflect.Bool:
 		b, err := d.readBoolean(_tagRead)
 		if err != nil {
 			return err
 		}
 		fldValue.SetBool(b)
-	case reflect.Float32, reflect.Float64:
-		f, err := d.readDouble(_tagRead)
-		if err != nil {
-			return err
-		}
-		fldValue.SetFloat(f)
 	case reflect.Struct:
+		d.expectType = typ
 		s, err := d.readStruct()
+		d.takeExpectType()
 		if err != nil {
 			return err
 		}
+		if o, ok := s.(*Object); ok && typ != _objectType {
+			return newCodecError("readField", "undefined type: %s", o.ClassName)
+		}
 		SetValue(sourceValue, EnsureRawValue(s))
+	case reflect.Interface:
+		return d.readInterfaceField(fldName, sourceValue)
 	case reflect.Map:
 		return d.readMap(sourceValue)
 	case reflect.Slice, reflect.Array:
+		d.expectType = typ
 		m, err := d.ReadList(_tagRead)
+		d.takeExpectType()
 		if err != nil {
 			if err == io.EOF {
 				break // ignore nil slice
@@ -379,3 +443,29 @@ func (d *Decoder) readField(fldName string, fldValue reflect.Value) error {
 
 	return nil
 }
+
+// readInterfaceField read the interface field, the value is decoded as the type registered for its class name,
+// and it's assigned to the field if it implements the interface of the field.
+func (d *Decoder) readInterfaceField(fldName string, dest reflect.Value) error {
+	data, err := EnsureInterface(d.ReadData())
+	if err != nil || data == nil {
+		return err
+	}
+
+	for dest.Kind() == reflect.Ptr {
+		if dest.IsNil() {
+			dest.Set(reflect.New(dest.Type().Elem()))
+		}
+		dest = dest.Elem()
+	}
+	v := reflect.ValueOf(data)
+	if !v.Type().Implements(dest.Type()) {
+		// the object is decoded as a pointer, whose element may implement the interface
+		if v.Kind() != reflect.Ptr || !v.Elem().Type().Implements(dest.Type()) {
+			return newCodecError("readInterfaceField", "field %s: %v does not implement %v", fldName, v.Type(), dest.Type())
+		}
+		v = v.Elem()
+	}
+	dest.Set(v)
+	return nil
+}
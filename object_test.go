@@ -0,0 +1,104 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package hessian
+
+import (
+	"bufio"
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type shapeI interface {
+	Area() float64
+}
+
+type circleT struct {
+	Radius float64
+}
+
+func (c circleT) Area() float64 {
+	return 3 * c.Radius * c.Radius
+}
+
+type squareT struct {
+	Side float64
+}
+
+func (s *squareT) Area() float64 {
+	return s.Side * s.Side
+}
+
+type drawingT struct {
+	Name   string
+	Shape  shapeI
+	Main   *shapeI
+	Shapes []shapeI
+	Extra  interface{}
+	Attrs  map[string]interface{}
+}
+
+func TestInterfaceField(t *testing.T) {
+	nameMap := map[string]string{"drawingT": "test.Drawing", "circleT": "test.Circle", "squareT": "test.Square"}
+	typMap := map[string]reflect.Type{
+		"test.Drawing": reflect.TypeOf(drawingT{}),
+		"test.Circle":  reflect.TypeOf(circleT{}),
+		"test.Square":  reflect.TypeOf(squareT{}),
+	}
+
+	var main shapeI = &squareT{Side: 3}
+	v := &drawingT{
+		Name:   "d",
+		Shape:  circleT{Radius: 1},
+		Main:   &main,
+		Shapes: []shapeI{&squareT{Side: 2}, circleT{Radius: 2}},
+		Extra:  []interface{}{int32(1), "a"},
+		Attrs:  map[string]interface{}{"square": &squareT{Side: 1}},
+	}
+
+	for _, version := range []int{ProtocolVersion2, ProtocolVersion1} {
+		bs, err := ToBytes(v, nameMap, WithProtocolVersion(version))
+		assert.Nil(t, err)
+
+		decoded, err := ToObject(bs, typMap, WithProtocolVersion(version))
+		assert.Nil(t, err)
+		d := decoded.(*drawingT)
+		assert.Equal(t, &circleT{Radius: 1}, d.Shape)
+		assert.Equal(t, &squareT{Side: 3}, *d.Main)
+		assert.Equal(t, 4.0, d.Shapes[0].Area())
+		assert.Equal(t, 12.0, d.Shapes[1].Area())
+		assert.Equal(t, []interface{}{int32(1), "a"}, d.Extra)
+		assert.Equal(t, &squareT{Side: 1}, d.Attrs["square"])
+
+		// the class must implement the interface of the field
+		_, err = ToObject(bs, map[string]reflect.Type{
+			"test.Drawing": reflect.TypeOf(drawingT{}),
+			"test.Circle":  reflect.TypeOf(struct{ Radius float64 }{}),
+			"test.Square":  reflect.TypeOf(squareT{}),
+		}, WithProtocolVersion(version))
+		assert.NotNil(t, err)
+	}
+
+	// decode into a named interface
+	var shape shapeI
+	bs, err := ToBytes(&squareT{Side: 5}, nameMap)
+	assert.Nil(t, err)
+	d := NewDecoder(bufio.NewReader(bytes.NewReader(bs)), typMap)
+	assert.Nil(t, d.DecodeInto(&shape))
+	assert.Equal(t, &squareT{Side: 5}, shape)
+}
@@ -0,0 +1,177 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package hessian
+
+import "time"
+
+// hessian protocol versions
+const (
+	// ProtocolVersionAuto the decoder detects the version from the leading bytes of the stream,
+	// and the encoder writes hessian 2.0.
+	ProtocolVersionAuto = 0
+	ProtocolVersion1    = 1
+	ProtocolVersion2    = 2
+)
+
+// Option option of encoder and decoder, an option not for the encoder or decoder is ignored by it
+type Option func(o *options)
+
+type options struct {
+	version      int
+	dateLocation *time.Location
+
+	// the java class names of go slices, maps and sets
+	javaListClass string
+	javaMapClass  string
+	javaSetClass  string
+
+	// decode the objects of unregistered classes as *Object
+	dynamicObject bool
+
+	// use the json tag as the field name if there is no hessian tag
+	jsonTag bool
+
+	// the naming strategy of the fields without tag names, and whether to match the field names case-insensitively
+	naming          *NamingStrategy
+	caseInsensitive bool
+
+	// the custom serializers by the java class name and the go type
+	serializerFactory *SerializerFactory
+
+	// the policy to convert the numbers to the numeric fields
+	numericPolicy NumericPolicy
+
+	// the policy to encode the unsigned values larger than math.MaxInt64
+	uintOverflowPolicy UintOverflowPolicy
+
+	// the max size of the content inflated from the deflation envelope
+	maxInflateSize int
+
+	// return an error for the struct fields shadowed by the fields with the same name
+	disallowShadowedFields bool
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		javaSetClass: JavaHashSet,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithProtocolVersion set the protocol version, default ProtocolVersionAuto.
+func WithProtocolVersion(version int) Option {
+	return func(o *options) {
+		o.version = version
+	}
+}
+
+// WithDateLocation set the location of the decoded dates, default the local time zone.
+func WithDateLocation(loc *time.Location) Option {
+	return func(o *options) {
+		o.dateLocation = loc
+	}
+}
+
+// WithJavaListClass set the java class name of the plain go slices for encoder,
+// default the untyped list which is java.util.ArrayList for java.
+func WithJavaListClass(name string) Option {
+	return func(o *options) {
+		o.javaListClass = name
+	}
+}
+
+// WithJavaMapClass set the java class name of the plain go maps for encoder,
+// default the untyped map which is java.util.HashMap for java.
+func WithJavaMapClass(name string) Option {
+	return func(o *options) {
+		o.javaMapClass = name
+	}
+}
+
+// WithJavaSetClass set the java class name of the go sets for encoder, default java.util.HashSet.
+func WithJavaSetClass(name string) Option {
+	return func(o *options) {
+		o.javaSetClass = name
+	}
+}
+
+// WithDynamicObject decode the objects whose classes are not registered in the type map as *Object for decoder,
+// instead of returning an error.
+func WithDynamicObject() Option {
+	return func(o *options) {
+		o.dynamicObject = true
+	}
+}
+
+// WithJSONTag use the json tag of struct field if there is no hessian tag, see the struct fields in field.go.
+func WithJSONTag() Option {
+	return func(o *options) {
+		o.jsonTag = true
+	}
+}
+
+// WithNamingStrategy set the naming strategy of the struct fields without tag names, default LowerCamelNaming.
+func WithNamingStrategy(naming *NamingStrategy) Option {
+	return func(o *options) {
+		o.naming = naming
+	}
+}
+
+// WithCaseInsensitive match the field names case-insensitively for decoder.
+func WithCaseInsensitive() Option {
+	return func(o *options) {
+		o.caseInsensitive = true
+	}
+}
+
+// WithSerializerFactory set the serializer factory of the custom serializers.
+func WithSerializerFactory(factory *SerializerFactory) Option {
+	return func(o *options) {
+		o.serializerFactory = factory
+	}
+}
+
+// WithNumericPolicy set the policy to convert the numbers to the numeric fields for decoder, default NumericStrict.
+func WithNumericPolicy(policy NumericPolicy) Option {
+	return func(o *options) {
+		o.numericPolicy = policy
+	}
+}
+
+// WithUintOverflowPolicy set the policy to encode the unsigned values larger than math.MaxInt64 for encoder, default UintOverflowError.
+func WithUintOverflowPolicy(policy UintOverflowPolicy) Option {
+	return func(o *options) {
+		o.uintOverflowPolicy = policy
+	}
+}
+
+// WithMaxInflateSize set the max size of the content inflated from the deflation envelope for decoder, default DefaultMaxInflateSize.
+func WithMaxInflateSize(size int) Option {
+	return func(o *options) {
+		o.maxInflateSize = size
+	}
+}
+
+// WithDisallowShadowedFields return an error when encoding or decoding a struct with the fields of the same name,
+// e.g. a field of an embedded struct shadowed by a field of the parent, instead of keeping them all, see field.go.
+func WithDisallowShadowedFields() Option {
+	return func(o *options) {
+		o.disallowShadowedFields = true
+	}
+}
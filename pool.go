@@ -55,22 +55,22 @@ func (p *objectPool) Return(o interface{}) {
 }
 
 //NewEncoderPool new pool for encoder
-func NewEncoderPool(size int, nameMap map[string]string) Pool {
+func NewEncoderPool(size int, nameMap map[string]string, opts ...Option) Pool {
 	return newPool(size, func() interface{} {
-		return NewEncoder(nil, nameMap)
+		return NewEncoder(nil, nameMap, opts...)
 	})
 }
 
 //NewDecoderPool new pool for decoder
-func NewDecoderPool(size int, typeMap map[string]reflect.Type) Pool {
+func NewDecoderPool(size int, typeMap map[string]reflect.Type, opts ...Option) Pool {
 	return newPool(size, func() interface{} {
-		return NewDecoder(nil, typeMap)
+		return NewDecoder(nil, typeMap, opts...)
 	})
 }
 
 //NewSerializerPool new pool for serializer
-func NewSerializerPool(size int, typeMap map[string]reflect.Type, nameMap map[string]string) Pool {
+func NewSerializerPool(size int, typeMap map[string]reflect.Type, nameMap map[string]string, opts ...Option) Pool {
 	return newPool(size, func() interface{} {
-		return NewSerializer(typeMap, nameMap)
+		return NewSerializer(typeMap, nameMap, opts...)
 	})
 }
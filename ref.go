@@ -74,6 +74,9 @@ func refTag(tag byte) bool {
 }
 
 func (e *Encoder) writeRef(index int) (int, error) {
+	if e.isHessian1() {
+		return e.writer.Write(encodeRef1(index))
+	}
 	e.writeBT(_refStartTag)
 	return e.writer.Write(encodeInt(int32(index)))
 }
@@ -81,6 +84,31 @@ func (e *Encoder) writeRef(index int) (int, error) {
 // return the order number of ref object if found ,
 // otherwise, add the object into the encode ref map
 func (e *Encoder) checkEncodeRefMap(v reflect.Value) (int, bool) {
+	kind, addr := refAddr(v)
+	if elem, ok := e.refMap[addr]; ok {
+		// the array addr is equal to the first elem, which must ignore
+		if elem.kind == kind {
+			// fmt.Printf("-----> find ref: %d, %p, %v, %v\n", elem.index, addr, kind, v)
+			return elem.index, ok
+		}
+		return 0, false
+	}
+
+	n := e.refCount
+	e.refMap[addr] = _refElem{kind, n}
+	e.refCount++
+	// fmt.Printf("---> add ref: %d, %p, %v, %v\n", n, addr, kind, v)
+	return 0, false
+}
+
+// aliasRef make the value refer to the ref index of another value written for it, e.g. by a custom serializer
+func (e *Encoder) aliasRef(v reflect.Value, index int) {
+	kind, addr := refAddr(v)
+	e.refMap[addr] = _refElem{kind, index}
+}
+
+// the kind and address of the value to identify the ref
+func refAddr(v reflect.Value) (reflect.Kind, unsafe.Pointer) {
 	var (
 		kind reflect.Kind
 		addr unsafe.Pointer
@@ -105,20 +133,16 @@ func (e *Encoder) checkEncodeRefMap(v reflect.Value) (int, bool) {
 			addr = unsafe.Pointer(PackPtr(v).Pointer())
 		}
 	}
+	return kind, addr
+}
 
-	if elem, ok := e.refMap[addr]; ok {
-		// the array addr is equal to the first elem, which must ignore
-		if elem.kind == kind {
-			// fmt.Printf("-----> find ref: %d, %p, %v, %v\n", elem.index, addr, kind, v)
-			return elem.index, ok
-		}
-		return 0, false
-	}
-
-	n := len(e.refMap)
-	e.refMap[addr] = _refElem{kind, n}
-	// fmt.Printf("---> add ref: %d, %p, %v, %v\n", n, addr, kind, v)
-	return 0, false
+// add a ref for the value which has no address, e.g. the fault map of a reply,
+// so that the following ref indexes are the same as the decoder.
+func (e *Encoder) addRefPlaceholder() int {
+	n := e.refCount
+	e.refMap[unsafe.Pointer(new(byte))] = _refElem{reflect.Invalid, n}
+	e.refCount++
+	return n
 }
 
 type _refHolder struct {
@@ -168,10 +192,18 @@ func (d *Decoder) addDecoderRef(v reflect.Value) *_refHolder {
 
 // read the ref reflect.Value , which may be one of type _refHolder
 func (d *Decoder) readRef(tag byte) (reflect.Value, error) {
-	if tag != _refStartTag {
+	var (
+		index int32
+		err   error
+	)
+	switch {
+	case tag == _refStartTag:
+		index, err = d.readInt(_tagRead)
+	case tag == _ref1Tag && d.isHessian1():
+		index, err = d.readInt(int32(_int4ByteStartTag))
+	default:
 		return _zeroValue, newCodecError("readRef", "error ref tag: 0x%x", tag)
 	}
-	index, err := d.readInt(_tagRead)
 	if err != nil {
 		return _zeroValue, err
 	}
@@ -16,7 +16,6 @@
 package hessian
 
 import (
-	"errors"
 	"fmt"
 	"reflect"
 	"strings"
@@ -417,10 +416,6 @@ func ConvertSliceValueType(destTyp reflect.Type, v reflect.Value) (reflect.Value
 		return _zeroValue, newCodecError("ConvertSliceValueType", "expect slice type, but get %v, objects: %v", k, v)
 	}
 
-	if v.Len() <= 0 {
-		return _zeroValue, nil
-	}
-
 	elemKind := destTyp.Elem().Kind()
 	elemPtrType := elemKind == reflect.Ptr
 	elemFloatType := FloatKind(elemKind)
@@ -437,7 +432,7 @@ func ConvertSliceValueType(destTyp reflect.Type, v reflect.Value) (reflect.Value
 			itemValue = reflect.ValueOf(item)
 		}
 
-		if !elemPtrType && itemValue.Kind() == reflect.Ptr {
+		if !elemPtrType && elemKind != reflect.Interface && itemValue.Kind() == reflect.Ptr {
 			itemValue = UnpackPtrValue(itemValue)
 		}
 
@@ -456,20 +451,6 @@ func ConvertSliceValueType(destTyp reflect.Type, v reflect.Value) (reflect.Value
 	return sl, nil
 }
 
-func findField(name string, typ reflect.Type) (int, error) {
-	for i := 0; i < typ.NumField(); i++ {
-		str := typ.Field(i).Name
-		if strings.Compare(str, name) == 0 {
-			return i, nil
-		}
-		str1 := capitalizeName(name)
-		if strings.Compare(str, str1) == 0 {
-			return i, nil
-		}
-	}
-	return 0, errors.New("no field " + name)
-}
-
 // SetValue set the value to dest.
 // It will auto check the Ptr pack level and unpack/pack to the right level.
 // It make sure success to set value
@@ -482,6 +463,17 @@ func SetValue(dest, v reflect.Value) {
 		}
 	}
 
+	// set the value implementing the interface directly
+	if dest.Kind() == reflect.Interface {
+		for v.IsValid() && v.Kind() == reflect.Interface && !v.IsNil() {
+			v = v.Elem()
+		}
+		if v.IsValid() && v.Type().Implements(dest.Type()) {
+			dest.Set(v)
+			return
+		}
+	}
+
 	// if the kind of dest is Ptr, the original value will be zero value
 	// set value on zero value is not allowed
 	// unpack to one-level pointer
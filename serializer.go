@@ -41,10 +41,10 @@ type goHessian struct {
 }
 
 //NewSerializer init
-func NewSerializer(typMap map[string]reflect.Type, nameMap map[string]string) Serializer {
+func NewSerializer(typMap map[string]reflect.Type, nameMap map[string]string, opts ...Option) Serializer {
 	return &goHessian{
-		encoder: NewEncoder(nil, nameMap),
-		decoder: NewDecoder(nil, typMap),
+		encoder: NewEncoder(nil, nameMap, opts...),
+		decoder: NewDecoder(nil, typMap, opts...),
 	}
 }
 
@@ -81,13 +81,13 @@ func (gh *goHessian) ToObject(bts []byte) (interface{}, error) {
 // ---------------------------------------------
 
 //Encode [NO-CACHE API] serialize object to bytes
-func ToBytes(object interface{}, nameMap map[string]string) ([]byte, error) {
-	e := NewEncoder(nil, nameMap)
+func ToBytes(object interface{}, nameMap map[string]string, opts ...Option) ([]byte, error) {
+	e := NewEncoder(nil, nameMap, opts...)
 	return e.Encode(object)
 }
 
 //Decode [NO-CACHE API] deserialize bytes to object
-func ToObject(ins []byte, typMap map[string]reflect.Type) (interface{}, error) {
-	d := NewDecoder(nil, typMap)
+func ToObject(ins []byte, typMap map[string]reflect.Type, opts ...Option) (interface{}, error) {
+	d := NewDecoder(nil, typMap, opts...)
 	return d.Decode(ins)
 }
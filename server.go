@@ -0,0 +1,211 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package hessian
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+// ServerDefaultMaxBodySize the default max size of the request body
+const ServerDefaultMaxBodySize = 8 * 1024 * 1024
+
+var _errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// Server a http.Handler which dispatches hessian calls to the exported methods of a go receiver.
+//
+// The method of a call is matched by the exact name or the capitalized name,
+// e.g. the java call "getUser" is dispatched to the go method "GetUser".
+// A method can return nothing, a value, an error, or a value and an error.
+// A fault is replied if the method returns an error or panics.
+type Server struct {
+	methods map[string]reflect.Value
+	typMap  map[string]reflect.Type
+	nameMap map[string]string
+	opts    []Option
+
+	maxBodySize int64
+}
+
+// ServerOption option to create server
+type ServerOption func(s *Server)
+
+// WithServerCodecOptions set the options of the encoder and decoder,
+// they are the same as those of NewSerializer.
+func WithServerCodecOptions(opts ...Option) ServerOption {
+	return func(s *Server) {
+		s.opts = append(s.opts, opts...)
+	}
+}
+
+// WithMaxBodySize set the max size of the request body, default ServerDefaultMaxBodySize
+func WithMaxBodySize(size int64) ServerOption {
+	return func(s *Server) {
+		s.maxBodySize = size
+	}
+}
+
+// NewServer create a server for the receiver,
+// the type map and name map are the same as those of NewSerializer,
+// and the maps are read only and shared by the concurrent requests.
+// The reply is written in the protocol version of the call.
+func NewServer(receiver interface{}, typMap map[string]reflect.Type, nameMap map[string]string, opts ...ServerOption) *Server {
+	v := reflect.ValueOf(receiver)
+	typ := v.Type()
+
+	methods := make(map[string]reflect.Value)
+	for i := 0; i < typ.NumMethod(); i++ {
+		m := typ.Method(i)
+		if m.PkgPath != "" || !validMethodResults(m.Type) {
+			continue
+		}
+		methods[m.Name] = v.Method(i)
+	}
+
+	s := &Server{
+		methods:     methods,
+		typMap:      typMap,
+		nameMap:     nameMap,
+		maxBodySize: ServerDefaultMaxBodySize,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func validMethodResults(typ reflect.Type) bool {
+	switch typ.NumOut() {
+	case 0, 1:
+		return true
+	case 2:
+		return typ.Out(1) == _errorType
+	default:
+		return false
+	}
+}
+
+// ServeHTTP decode the call from request, and write the reply to response
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Hessian requires POST", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, s.maxBodySize)
+	decoder := NewDecoder(bufio.NewReader(body), s.typMap, s.opts...)
+	call, err := decoder.ReadCall()
+
+	reply := bytes.NewBuffer(nil)
+	encoderOpts := append([]Option{}, s.opts...)
+	if decoder.protocolVersion != ProtocolVersionAuto {
+		encoderOpts = append(encoderOpts, WithProtocolVersion(decoder.protocolVersion))
+	}
+	encoder := NewEncoder(reply, s.nameMap, encoderOpts...)
+
+	if err != nil {
+		encoder.WriteFault(FaultProtocolException, err.Error(), nil)
+	} else if value, fault := s.invoke(call); fault != nil {
+		encoder.WriteFault(fault.Code, fault.Message, fault.Detail)
+	} else if err = encoder.WriteReply(value); err != nil {
+		reply.Reset()
+		encoder.Reset(reply)
+		encoder.WriteFault(FaultServiceException, err.Error(), nil)
+	}
+
+	w.Header().Set("Content-Type", _contentType)
+	w.Write(reply.Bytes())
+}
+
+func (s *Server) invoke(call *Call) (value interface{}, fault *Fault) {
+	method, ok := s.methods[call.Method]
+	if !ok && call.Method != "" {
+		method, ok = s.methods[capitalizeName(call.Method)]
+	}
+	if !ok {
+		return nil, &Fault{Code: FaultNoSuchMethodException, Message: "no method " + call.Method}
+	}
+
+	typ := method.Type()
+	if typ.NumIn() != len(call.Args) || typ.IsVariadic() {
+		return nil, &Fault{
+			Code:    FaultNoSuchMethodException,
+			Message: fmt.Sprintf("method %s requires %d arguments, but get %d", call.Method, typ.NumIn(), len(call.Args)),
+		}
+	}
+
+	in := make([]reflect.Value, len(call.Args))
+	for i, arg := range call.Args {
+		v, err := convertArgument(arg, typ.In(i))
+		if err != nil {
+			return nil, &Fault{
+				Code:    FaultProtocolException,
+				Message: fmt.Sprintf("method %s argument %d: %v", call.Method, i, err),
+			}
+		}
+		in[i] = v
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			value = nil
+			fault = &Fault{Code: FaultServiceException, Message: fmt.Sprintf("method %s panic: %v", call.Method, r)}
+		}
+	}()
+
+	out := method.Call(in)
+	if n := len(out); n > 0 && typ.Out(n-1) == _errorType {
+		if err, _ := out[n-1].Interface().(error); err != nil {
+			return nil, &Fault{Code: FaultServiceException, Message: err.Error()}
+		}
+		out = out[:n-1]
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out[0].Interface(), nil
+}
+
+// convert the decoded argument to the parameter type
+func convertArgument(arg interface{}, typ reflect.Type) (v reflect.Value, err error) {
+	v = reflect.New(typ).Elem()
+	if arg == nil {
+		return v, nil
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("can't convert %v to %v", reflect.TypeOf(arg), typ)
+		}
+	}()
+
+	switch typ.Kind() {
+	case reflect.Interface:
+		argValue := reflect.ValueOf(arg)
+		if !argValue.Type().Implements(typ) {
+			return v, fmt.Errorf("%v not implements %v", argValue.Type(), typ)
+		}
+		v.Set(argValue)
+	case reflect.Slice:
+		err = SetSlice(v, arg)
+	default:
+		SetValue(v, EnsureRawValue(arg))
+	}
+	return v, err
+}
@@ -0,0 +1,163 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package hessian
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type pointServiceT struct{}
+
+func (pointServiceT) Sum(p *P) int {
+	return p.X + p.Y + p.Z
+}
+
+func (pointServiceT) Move(p P, x int64) P {
+	p.X += int(x)
+	return p
+}
+
+func (pointServiceT) Divide(a, b int32) (int32, error) {
+	if b == 0 {
+		return 0, errors.New("divide by zero")
+	}
+	return a / b, nil
+}
+
+func (pointServiceT) Join(names []string) string {
+	return strings.Join(names, ",")
+}
+
+func (pointServiceT) Crash() {
+	panic("crash")
+}
+
+func newPointServiceClient() (*Client, func()) {
+	typMap, nameMap := ExtractTypeNameMap(P{})
+	server := httptest.NewServer(NewServer(pointServiceT{}, typMap, nameMap))
+	return NewClient(server.URL, typMap, nameMap), server.Close
+}
+
+func TestServer(t *testing.T) {
+	client, closeServer := newPointServiceClient()
+	defer closeServer()
+
+	sum, err := client.Invoke("sum", &P{X: 1, Y: 2, Z: 3})
+	assert.Nil(t, err)
+	assert.Equal(t, int32(6), sum)
+
+	moved, err := client.Invoke("Move", P{X: 1, Name: "p"}, int64(2))
+	assert.Nil(t, err)
+	assert.Equal(t, &P{X: 3, Name: "p"}, moved)
+
+	quotient, err := client.Invoke("divide", int32(6), int32(3))
+	assert.Nil(t, err)
+	assert.Equal(t, int32(2), quotient)
+
+	joined, err := client.Invoke("join", []string{"a", "b"})
+	assert.Nil(t, err)
+	assert.Equal(t, "a,b", joined)
+}
+
+func TestServerConcurrent(t *testing.T) {
+	// the replies of the classes not in the name map are written by the go type names
+	typMap, nameMap := ExtractTypeNameMap(P{})
+	serverNameMap := map[string]string{}
+	server := NewServer(pointServiceT{}, typMap, serverNameMap)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			body := bytes.NewBuffer(nil)
+			assert.Nil(t, NewEncoder(body, nameMap).WriteCall("move", P{X: i}, int64(1)))
+
+			res := httptest.NewRecorder()
+			server.ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/", body))
+			moved, err := NewDecoder(bufio.NewReader(res.Body), typMap).ReadReply()
+			assert.Nil(t, err)
+			assert.Equal(t, &P{X: i + 1}, moved)
+		}(i)
+	}
+	wg.Wait()
+
+	// the name map shared by the requests is not changed
+	assert.Empty(t, serverNameMap)
+}
+
+func TestServerFault(t *testing.T) {
+	client, closeServer := newPointServiceClient()
+	defer closeServer()
+
+	assertFault := func(err error, code string, message string) {
+		fault, ok := err.(*Fault)
+		if assert.True(t, ok, "expect fault but get %v", err) {
+			assert.Equal(t, code, fault.Code)
+			assert.Contains(t, fault.Message, message)
+		}
+	}
+
+	_, err := client.Invoke("divide", int32(6), int32(0))
+	assertFault(err, FaultServiceException, "divide by zero")
+
+	_, err = client.Invoke("crash")
+	assertFault(err, FaultServiceException, "panic: crash")
+
+	_, err = client.Invoke("multiply", int32(6), int32(0))
+	assertFault(err, FaultNoSuchMethodException, "no method multiply")
+
+	_, err = client.Invoke("divide", int32(6))
+	assertFault(err, FaultNoSuchMethodException, "requires 2 arguments")
+
+	_, err = client.Invoke("divide", "6", int32(1))
+	assertFault(err, FaultProtocolException, "argument 0")
+}
+
+func TestServerRequiresPost(t *testing.T) {
+	server := httptest.NewServer(NewServer(pointServiceT{}, nil, nil))
+	defer server.Close()
+
+	res, err := http.Get(server.URL)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusMethodNotAllowed, res.StatusCode)
+}
+
+func TestServerMaxBodySize(t *testing.T) {
+	server := httptest.NewServer(NewServer(pointServiceT{}, nil, nil, WithMaxBodySize(64)))
+	defer server.Close()
+	client := NewClient(server.URL, nil, nil)
+
+	joined, err := client.Invoke("join", []string{"a", "b"})
+	assert.Nil(t, err)
+	assert.Equal(t, "a,b", joined)
+
+	_, err = client.Invoke("join", []string{strings.Repeat("a", 64)})
+	fault, ok := err.(*Fault)
+	if assert.True(t, ok, "expect fault but get %v", err) {
+		assert.Equal(t, FaultProtocolException, fault.Code)
+		assert.Contains(t, fault.Message, "too large")
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+// see: http://hessian.caucho.com/doc/hessian-ws.html
+//
+// Packet Grammar
+//
+// top     ::= version packet*
+// version ::= 'H' x02 x00
+// packet  ::= x4f b1 b0 <data> packet  # non-final chunk
+//         ::= 'P' b1 b0 <data>         # final chunk
+//         ::= [x70-x7f] <data>         # final chunk, length = code - 0x70
+//         ::= [x80-xff] b0 <data>      # final chunk, length = (code - 0x80) << 8 + b0
+//
+// A packet contains one value, the refs and the class definitions are reset for each packet,
+// so that a long-lived stream (e.g. net.Conn) can carry independent messages.
+//
+// -------------- Packet examples
+//
+// H x02 x00         # hessian 2.0
+// x75               # packet with 5 bytes
+//   x05 hello       # "hello"
+// x71               # packet with 1 byte
+//   x95             # int 5
+
+package hessian
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"reflect"
+)
+
+const (
+	_packetChunk           = byte(0x4f)
+	_packetFinalChunk      = byte('P')
+	_packetShortLenTagMin  = byte(0x70)
+	_packetShortLenTagMax  = byte(0x7f)
+	_packetMediumLenTagMin = byte(0x80)
+	_packetShortMaxLen     = int(_packetShortLenTagMax - _packetShortLenTagMin)
+	_packetMediumMaxLen    = 0x7fff
+	_packetChunkSize       = 0x8000
+)
+
+var _packetChunkSizeBytes = []byte{byte(_packetChunkSize >> 8), byte(_packetChunkSize & 0xff)}
+
+// StreamEncoder write values as hessian 2.0 packets,
+// which can be read by StreamDecoder or java Hessian2StreamingInput.
+type StreamEncoder struct {
+	writer         io.Writer
+	encoder        *Encoder
+	buffer         *bytes.Buffer
+	versionWritten bool
+}
+
+// NewStreamEncoder create a stream encoder, the version header will be written before the first packet.
+func NewStreamEncoder(w io.Writer, nameMap map[string]string, opts ...Option) *StreamEncoder {
+	// packet is a feature of hessian 2.0
+	opts = append(append([]Option{}, opts...), WithProtocolVersion(ProtocolVersion2))
+	return &StreamEncoder{
+		writer:  w,
+		encoder: NewEncoder(nil, nameMap, opts...),
+		buffer:  bytes.NewBuffer(nil),
+	}
+}
+
+// WritePacket write the value as a packet
+func (s *StreamEncoder) WritePacket(value interface{}) error {
+	s.buffer.Reset()
+	if !s.versionWritten {
+		s.buffer.Write([]byte{_versionTag, _versionMajor, _versionMinor})
+	}
+
+	data, err := s.encoder.Encode(value)
+	if err != nil {
+		return newCodecError("WritePacket", err)
+	}
+
+	length := len(data)
+	begin := 0
+	for length > _packetMediumMaxLen {
+		s.buffer.WriteByte(_packetChunk)
+		s.buffer.Write(_packetChunkSizeBytes)
+		s.buffer.Write(data[begin : begin+_packetChunkSize])
+
+		length -= _packetChunkSize
+		begin += _packetChunkSize
+	}
+
+	if length <= _packetShortMaxLen {
+		s.buffer.WriteByte(_packetShortLenTagMin + byte(length))
+	} else {
+		s.buffer.Write([]byte{_packetMediumLenTagMin + byte(length>>8), byte(length)})
+	}
+	s.buffer.Write(data[begin:])
+
+	if _, err = s.writer.Write(s.buffer.Bytes()); err != nil {
+		return newCodecError("WritePacket", err)
+	}
+	s.versionWritten = true
+	return nil
+}
+
+// StreamDecoder read values from hessian 2.0 packets,
+// which are written by StreamEncoder or java Hessian2StreamingOutput.
+type StreamDecoder struct {
+	reader  *bufio.Reader
+	decoder *Decoder
+}
+
+// NewStreamDecoder create a stream decoder
+func NewStreamDecoder(r io.Reader, typMap map[string]reflect.Type, opts ...Option) *StreamDecoder {
+	reader, ok := r.(*bufio.Reader)
+	if !ok {
+		reader = bufio.NewReader(r)
+	}
+	opts = append(append([]Option{}, opts...), WithProtocolVersion(ProtocolVersion2))
+	return &StreamDecoder{
+		reader:  reader,
+		decoder: NewDecoder(nil, typMap, opts...),
+	}
+}
+
+// ReadPacket read the value of next packet, io.EOF is returned at the end of the stream.
+func (s *StreamDecoder) ReadPacket() (interface{}, error) {
+	data, err := s.readPacketData()
+	if err != nil {
+		return nil, err
+	}
+	return s.decoder.ReadFrom(bufio.NewReader(bytes.NewReader(data)))
+}
+
+func (s *StreamDecoder) readPacketData() ([]byte, error) {
+	var data []byte
+	for {
+		tag, err := s.reader.ReadByte()
+		if err != nil {
+			if err == io.EOF && data == nil {
+				return nil, io.EOF
+			}
+			return nil, newCodecError("ReadPacket", io.ErrUnexpectedEOF)
+		}
+
+		// the version header
+		if tag == _versionTag && data == nil {
+			version, err := readBytes(s.reader, 2)
+			if err != nil {
+				return nil, newCodecError("ReadPacket", err)
+			}
+			if version[0] != _versionMajor {
+				return nil, newCodecError("ReadPacket", "unsupported version: %d.%d", version[0], version[1])
+			}
+			continue
+		}
+
+		final := true
+		length := 0
+		switch {
+		case tag == _packetChunk || tag == _packetFinalChunk:
+			final = tag == _packetFinalChunk
+			buf, err := readBytes(s.reader, 2)
+			if err != nil {
+				return nil, newCodecError("ReadPacket", err)
+			}
+			length = int(buf[0])<<8 + int(buf[1])
+		case tag >= _packetShortLenTagMin && tag <= _packetShortLenTagMax:
+			length = int(tag - _packetShortLenTagMin)
+		case tag >= _packetMediumLenTagMin:
+			b0, err := s.reader.ReadByte()
+			if err != nil {
+				return nil, newCodecError("ReadPacket", err)
+			}
+			length = int(tag-_packetMediumLenTagMin)<<8 + int(b0)
+		default:
+			return nil, newCodecError("ReadPacket", "error packet tag: 0x%x", tag)
+		}
+
+		chunk, err := readBytes(s.reader, length)
+		if err != nil {
+			return nil, newCodecError("ReadPacket", err)
+		}
+		data = append(data, chunk...)
+		if data == nil {
+			data = []byte{}
+		}
+
+		if final {
+			return data, nil
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2019 vogo.
+// Author: wongoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package hessian
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamPacket(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	encoder := NewStreamEncoder(buf, nil)
+	assert.Nil(t, encoder.WritePacket("hello"))
+	assert.Nil(t, encoder.WritePacket(int32(5)))
+	assert.Equal(t, []byte{'H', 0x02, 0x00, 0x76, 0x05, 'h', 'e', 'l', 'l', 'o', 0x71, 0x95}, buf.Bytes())
+
+	decoder := NewStreamDecoder(buf, nil)
+	v, err := decoder.ReadPacket()
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", v)
+	v, err = decoder.ReadPacket()
+	assert.Nil(t, err)
+	assert.Equal(t, int32(5), v)
+	_, err = decoder.ReadPacket()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestStreamPacketChunks(t *testing.T) {
+	medium := strings.Repeat("m", 1000)
+	large := strings.Repeat("l", _packetChunkSize*2+10)
+
+	buf := bytes.NewBuffer(nil)
+	encoder := NewStreamEncoder(buf, nil)
+	assert.Nil(t, encoder.WritePacket(medium))
+	assert.Equal(t, []byte{'H', 0x02, 0x00, 0x80 + 0x03, 0xea}, buf.Bytes()[:5])
+	assert.Nil(t, encoder.WritePacket(large))
+
+	// 'P' final chunk written by java
+	buf.Write([]byte{'P', 0x00, 0x01, 0x95})
+
+	decoder := NewStreamDecoder(buf, nil)
+	for _, expect := range []interface{}{medium, large, int32(5)} {
+		v, err := decoder.ReadPacket()
+		assert.Nil(t, err)
+		assert.Equal(t, expect, v)
+	}
+
+	_, err := NewStreamDecoder(bytes.NewReader([]byte{0x75, 0x05}), nil).ReadPacket()
+	assert.NotNil(t, err)
+}
+
+func TestStreamPacketRefReset(t *testing.T) {
+	c := buildSingleCircularObject()
+	typMap, nameMap := ExtractTypeNameMap(c)
+
+	client, server := net.Pipe()
+	go func() {
+		encoder := NewStreamEncoder(client, nameMap)
+		for i := 0; i < 3; i++ {
+			encoder.WritePacket(c)
+		}
+		client.Close()
+	}()
+
+	decoder := NewStreamDecoder(server, typMap)
+	for i := 0; i < 3; i++ {
+		v, err := decoder.ReadPacket()
+		assert.Nil(t, err)
+		d, ok := v.(*circularT)
+		if assert.True(t, ok) {
+			assert.Equal(t, c.Num, d.Num)
+			assert.True(t, d == d.Next)
+		}
+	}
+	_, err := decoder.ReadPacket()
+	assert.Equal(t, io.EOF, err)
+}
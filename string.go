@@ -32,6 +32,10 @@
 //
 // String chunks may not split surrogate pairs.
 //
+// As java does, a character out of the basic multilingual plane is counted as 2 characters,
+// and its surrogate pair is encoded separately with 3 bytes each.
+// A 4 bytes utf-8 character is also accepted when decoding, which is counted as 2 characters.
+//
 // short strings
 // Strings with length less than 32 may be encoded with a single octet length [x00-x1f].
 // 	value = code
@@ -50,11 +54,11 @@ package hessian
 import (
 	"bytes"
 	"io"
-	"strings"
+	"unicode/utf16"
 )
 
 const (
-	_stringChunkSize  = 2048
+	_stringChunkSize  = 0x8000 // the same as java
 	_stringFinalChunk = byte('S') // final string
 	_stringChunk      = byte('R') // non-final string
 
@@ -73,31 +77,25 @@ var (
 )
 
 func encodeString(value string) []byte {
-
-	if value == "" {
-		return []byte{_nilTag}
-	}
-
-	dataBys := []rune(value)
-	length := len(dataBys)
+	units := utf16Units(value)
+	length := len(units)
 	byteBuf := bytes.NewBuffer(nil)
 
 	begin := 0
 	// ----> chunk string
 	for length > _stringChunkSize {
-		byteBuf.WriteByte(_stringChunk)
-		byteBuf.Write(StringChunkSizeBytes)
+		sublen := stringChunkLen(units, begin, _stringChunkSize)
+		byteBuf.Write([]byte{_stringChunk, byte(sublen >> 8), byte(sublen)})
+		writeUTF16(byteBuf, units[begin:begin+sublen])
 
-		byteBuf.Write([]byte(string(dataBys[begin : begin+_stringChunkSize])))
-
-		length -= _stringChunkSize
-		begin += _stringChunkSize
+		length -= sublen
+		begin += sublen
 	}
 
 	// ----> short string
 	if length <= _stringShortMaxLen {
 		byteBuf.WriteByte(byte(int(_stringShortLenMin) + length))
-		byteBuf.Write([]byte(string(dataBys[begin:])))
+		writeUTF16(byteBuf, units[begin:])
 		return byteBuf.Bytes()
 	}
 
@@ -105,7 +103,7 @@ func encodeString(value string) []byte {
 	if length <= _stringMiddleMaxLen {
 		byteBuf.WriteByte(byte((length >> 8) + int(_stringMiddleLenMin)))
 		byteBuf.WriteByte(byte(length))
-		byteBuf.Write([]byte(string(dataBys[begin:])))
+		writeUTF16(byteBuf, units[begin:])
 		return byteBuf.Bytes()
 	}
 
@@ -113,7 +111,7 @@ func encodeString(value string) []byte {
 	byteBuf.WriteByte(_stringFinalChunk)
 	byteBuf.WriteByte(byte(length >> 8))
 	byteBuf.WriteByte(byte(length))
-	byteBuf.Write([]byte(string(dataBys[begin:])))
+	writeUTF16(byteBuf, units[begin:])
 	return byteBuf.Bytes()
 }
 
@@ -137,14 +135,11 @@ func decodeStringValue(reader ByteRuneReader, flag int32) (string, error) {
 		return "", err
 	}
 
-	byteBuf := bytes.NewBuffer(nil)
-	buf := make([]rune, length)
+	units := make([]uint16, 0, length)
 	for {
-		read, err := readRunes(reader, buf)
-		if err != nil && !strings.Contains(err.Error(), "EOF") {
+		if units, err = readUTF16(reader, length, units); err != nil {
 			return "", err
 		}
-		byteBuf.Write([]byte(string(buf[:read])))
 
 		if stringEndTag(tag) {
 			break
@@ -163,17 +158,12 @@ func decodeStringValue(reader ByteRuneReader, flag int32) (string, error) {
 			return "", newCodecError("decodeStringValue", "error string tag: 0x%x", tag)
 		}
 
-		newLength, err := getStringLen(reader, tag)
-		if err != nil {
+		if length, err = getStringLen(reader, tag); err != nil {
 			return "", err
 		}
-		if newLength < length {
-			buf = buf[:newLength]
-			length = newLength
-		}
 	}
 
-	return string(byteBuf.Bytes()), nil
+	return string(utf16.Decode(units)), nil
 }
 
 func stringShortTag(tag byte) bool {
@@ -225,3 +215,77 @@ func getStringLen(reader ByteRuneReader, tag byte) (int, error) {
 	return -1, newCodecError("getStringLen", "err string tag: 0x%x", tag)
 
 }
+
+// utf16Units convert the string to utf-16 code units, which is the same as the chars of java string
+func utf16Units(value string) []uint16 {
+	return utf16.Encode([]rune(value))
+}
+
+// stringChunkLen return the length of the chunk from begin, a chunk never ends with a high surrogate
+func stringChunkLen(units []uint16, begin, size int) int {
+	if tail := units[begin+size-1]; tail >= 0xd800 && tail <= 0xdbff {
+		return size - 1
+	}
+	return size
+}
+
+// writeUTF16 write the utf-16 code units in utf-8, a surrogate is written in 3 bytes as java does
+func writeUTF16(buf *bytes.Buffer, units []uint16) {
+	for _, u := range units {
+		switch {
+		case u < 0x80:
+			buf.WriteByte(byte(u))
+		case u < 0x800:
+			buf.Write([]byte{0xc0 | byte(u>>6), 0x80 | byte(u&0x3f)})
+		default:
+			buf.Write([]byte{0xe0 | byte(u>>12), 0x80 | byte((u>>6)&0x3f), 0x80 | byte(u&0x3f)})
+		}
+	}
+}
+
+// readUTF16 read the utf-8 data of length utf-16 code units, and append the code units to units.
+func readUTF16(reader ByteRuneReader, length int, units []uint16) ([]uint16, error) {
+	for n := 0; n < length; {
+		b, err := readByte(reader)
+		if err != nil {
+			return units, err
+		}
+
+		var size int
+		var r rune
+		switch {
+		case b < 0x80:
+			units = append(units, uint16(b))
+			n++
+			continue
+		case b&0xe0 == 0xc0:
+			size, r = 1, rune(b&0x1f)
+		case b&0xf0 == 0xe0:
+			size, r = 2, rune(b&0x0f)
+		case b&0xf8 == 0xf0:
+			size, r = 3, rune(b&0x07)
+		default:
+			return units, newCodecError("readUTF16", "error utf-8 byte: 0x%x", b)
+		}
+
+		for i := 0; i < size; i++ {
+			if b, err = readByte(reader); err != nil {
+				return units, err
+			}
+			if b&0xc0 != 0x80 {
+				return units, newCodecError("readUTF16", "error utf-8 byte: 0x%x", b)
+			}
+			r = r<<6 | rune(b&0x3f)
+		}
+
+		if r > 0xffff {
+			r1, r2 := utf16.EncodeRune(r)
+			units = append(units, uint16(r1), uint16(r2))
+			n += 2
+			continue
+		}
+		units = append(units, uint16(r))
+		n++
+	}
+	return units, nil
+}
@@ -81,3 +81,38 @@ func stringTest(t *testing.T, str string) {
 func TestRuneString(t *testing.T) {
 	stringTest(t, "hello world 你好世界...")
 }
+
+func TestSupplementaryString(t *testing.T) {
+	// counted as 2 characters, and the surrogates are encoded separately as java does
+	assert.Equal(t, []byte{0x02, 0xed, 0xa0, 0xbd, 0xed, 0xb8, 0x80}, encodeString("😀"))
+	assert.Equal(t, []byte{0x03, 'a', 0xed, 0xa0, 0xbd, 0xed, 0xb8, 0x80}, encodeString("a😀"))
+	stringTest(t, "hello 😀 世界 𝄞")
+
+	// 4 bytes utf-8 is also accepted
+	decoded, err := decodeString(bufio.NewReader(bytes.NewReader([]byte{0x03, 0xf0, 0x9f, 0x98, 0x80, 'a'})))
+	assert.Nil(t, err)
+	assert.Equal(t, "😀a", decoded)
+
+	// the surrogate pair is not split by chunks
+	str := randomString(_stringChunkSize-1) + "😀b"
+	bs := encodeString(str)
+	assert.Equal(t, []byte{_stringChunk, 0x7f, 0xff}, bs[:3])
+	assert.Equal(t, []byte{0x03, 0xed, 0xa0, 0xbd, 0xed, 0xb8, 0x80, 'b'}, bs[len(bs)-8:])
+	stringTest(t, str)
+
+	// the surrogate pair split by chunks written by others
+	decoded, err = decodeString(bufio.NewReader(bytes.NewReader([]byte{
+		_stringChunk, 0x00, 0x02, 'a', 0xed, 0xa0, 0xbd,
+		0x02, 0xed, 0xb8, 0x80, 'b',
+	})))
+	assert.Nil(t, err)
+	assert.Equal(t, "a😀b", decoded)
+
+	// hessian 1.0
+	bs = encodeString1(str)
+	assert.Equal(t, []byte{_string1Chunk, 0x7f, 0xff}, bs[:3])
+	decoded, err = decodeString1Value(bufio.NewReader(bytes.NewReader(bs)), _tagRead)
+	assert.Nil(t, err)
+	assert.Equal(t, str, decoded)
+	assert.Equal(t, []byte{'S', 0x00, 0x02, 0xed, 0xa0, 0xbd, 0xed, 0xb8, 0x80}, encodeString1("😀"))
+}
@@ -70,14 +70,10 @@ func readBytes(reader ByteRuneReader, length int) ([]byte, error) {
 	return buf, nil
 }
 
-func readRunes(reader io.RuneReader, buf []rune) (int, error) {
-	i := 0
-	for ; i < len(buf); i++ {
-		r, _, err := reader.ReadRune()
-		if err != nil {
-			return i, err
-		}
-		buf[i] = r
+// readByte read a byte, the reader is read directly if it's a io.ByteReader
+func readByte(reader ByteRuneReader) (byte, error) {
+	if br, ok := reader.(io.ByteReader); ok {
+		return br.ReadByte()
 	}
-	return i, nil
+	return readTag(reader)
 }